@@ -0,0 +1,79 @@
+package gorka
+
+// ShortestHopPath returns a path from a to b with the least number of edges
+// ignoring weights, or ErrPathNotFound. It runs breadth-first searches from
+// both ends at once, forward over outgoing edges from a and backward over
+// incoming edges from b, always expanding the smaller frontier by a whole
+// level, and stops when they meet. On large sparse graphs this explores
+// a tiny part of what a single BFS from a does.
+func ShortestHopPath(g Graph, a, b Node) ([]Edge, error) {
+	if a.ID() == b.ID() {
+		return []Edge{}, nil
+	}
+	size := g.MaxNodeID() + 1
+	inEdges := inEdgeIter(g)
+	// edges leading to the node from a in the forward tree
+	// and from the node to b in the backward tree
+	fwd := make([]Edge, size)
+	bwd := make([]Edge, size)
+	fseen := make([]bool, size)
+	bseen := make([]bool, size)
+	fseen[a.ID()] = true
+	bseen[b.ID()] = true
+	ffront, bfront := []Node{a}, []Node{b}
+
+	var meet Node
+	for len(ffront) > 0 && len(bfront) > 0 {
+		var next []Node
+		if len(ffront) <= len(bfront) {
+			for _, n := range ffront {
+				g.NodeEdgeIter(n, func(e Edge) bool {
+					d := e.Dst()
+					if !fseen[d.ID()] {
+						fseen[d.ID()] = true
+						fwd[d.ID()] = e
+						next = append(next, d)
+						if meet == nil && bseen[d.ID()] {
+							meet = d
+						}
+					}
+					return true
+				})
+			}
+			ffront = next
+		} else {
+			for _, n := range bfront {
+				inEdges(n, func(e Edge) bool {
+					d := e.From()
+					if !bseen[d.ID()] {
+						bseen[d.ID()] = true
+						bwd[d.ID()] = e
+						next = append(next, d)
+						if meet == nil && fseen[d.ID()] {
+							meet = d
+						}
+					}
+					return true
+				})
+			}
+			bfront = next
+		}
+		// any node met in the completed level is on a shortest path
+		if meet != nil {
+			break
+		}
+	}
+	if meet == nil {
+		return nil, ErrPathNotFound
+	}
+
+	var path []Edge
+	for e := fwd[meet.ID()]; e != nil; e = fwd[e.From().ID()] {
+		path = append(path, e)
+	}
+	reversePath(path)
+	for e := bwd[meet.ID()]; e != nil; e = bwd[e.Dst().ID()] {
+		path = append(path, e)
+	}
+	return path, nil
+}
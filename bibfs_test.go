@@ -0,0 +1,94 @@
+package gorka
+
+import (
+	"testing"
+
+	"github.com/iimos/gorka/gralang"
+)
+
+func TestShortestHopPath(t *testing.T) {
+	type tcase struct {
+		s        string
+		from, to string
+		hops     int // -1 if unreachable
+	}
+	cases := [...]tcase{
+		tcase{"a -> b", "a", "a", 0},
+		tcase{"a -> b", "a", "b", 1},
+		tcase{"a -> b", "b", "a", -1},
+		tcase{"a -> b -> c -> d -> e; a -> x -> e", "a", "e", 2},
+		tcase{"a -> b -> c -> d; x -> d", "a", "d", 3},
+		tcase{"a -> b c d; b -> e; c -> e; d -> e; e -> f", "a", "f", 3},
+		tcase{"a -> b; c -> d", "a", "d", -1},
+		tcase{"a -- b -- c -- d -- e -- f", "f", "a", 5},
+	}
+
+	for i, c := range cases {
+		g := New()
+		gralang.Parse(g, c.s)
+		a, _ := g.NodeByLabel(c.from)
+		b, _ := g.NodeByLabel(c.to)
+
+		path, err := ShortestHopPath(g, a, b)
+		if c.hops < 0 {
+			if err != ErrPathNotFound {
+				t.Errorf("#%d: expected ErrPathNotFound, got %v %v", i, path, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("#%d: error %s", i, err)
+			continue
+		}
+		checkHopPath(t, i, a, b, path, c.hops)
+	}
+}
+
+func checkHopPath(t *testing.T, i int, a, b Node, path []Edge, hops int) {
+	if len(path) != hops {
+		t.Errorf("#%d: wrong path length %d, expected %d: %v", i, len(path), hops, path)
+		return
+	}
+	if hops == 0 {
+		return
+	}
+	if path[0].From() != a || path[len(path)-1].Dst() != b {
+		t.Errorf("#%d: path doesn't connect %s and %s: %v", i, a, b, path)
+	}
+	for j := 1; j < len(path); j++ {
+		if path[j-1].Dst() != path[j].From() {
+			t.Errorf("#%d: broken path %v", i, path)
+		}
+	}
+}
+
+func TestShortestHopPathRandom(t *testing.T) {
+	g := sparseRandom(3000, 6000, 3)
+	nodes := g.(*graph).nodes
+	for i := 0; i < 50; i++ {
+		a, b := nodes[i*37%len(nodes)], nodes[i*101%len(nodes)+1]
+		hops := -1
+		TraverseBreadthFirstVisit(g, a, nil, func(v Visit) bool {
+			if v.Node == b {
+				hops = v.Depth
+				return false
+			}
+			return true
+		})
+
+		for _, gg := range []Graph{g, outOnly{g}} {
+			path, err := ShortestHopPath(gg, a, b)
+			if hops < 0 {
+				if err != ErrPathNotFound {
+					t.Errorf("#%d: expected ErrPathNotFound, got %v", i, err)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("#%d: error %s", i, err)
+				continue
+			}
+			checkHopPath(t, i, a, b, path, hops)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package gorka
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"math"
+	"sort"
+)
+
+// Binary graph format:
+//
+//	magic "GRKB", version byte, flags byte
+//	uvarint nodes count
+//	labels if flagLabels: uvarint length and bytes per node
+//	per node: uvarint out degree, destination node indexes sorted and
+//	          delta-encoded as uvarints, and if flagWeights float32 weights
+//	CRC-32 (Castagnoli) of all the above, little endian
+//
+// Nodes are referenced by their position in NodeIter order.
+// Weights are omitted when all of them are 1.
+
+const (
+	binaryMagic   = "GRKB"
+	binaryVersion = 1
+
+	flagLabels  = 1 << 0
+	flagWeights = 1 << 1
+)
+
+// ErrBadChecksum means that binary graph data is corrupted
+var ErrBadChecksum = errors.New("binary graph checksum mismatch")
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// WriteBinary writes the graph in compact binary format
+func WriteBinary(w io.Writer, g Graph) error {
+	index := make([]int, g.MaxNodeID()+1)
+	var flags byte
+	count := 0
+	g.NodeIter(func(n Node) bool {
+		index[n.ID()] = count
+		count++
+		if n.Label() != "" {
+			flags |= flagLabels
+		}
+		if flags&flagWeights == 0 {
+			g.NodeEdgeIter(n, func(e Edge) bool {
+				if e.Wieght() != 1 {
+					flags |= flagWeights
+					return false
+				}
+				return true
+			})
+		}
+		return true
+	})
+
+	crc := crc32.New(crcTable)
+	b := bufio.NewWriter(io.MultiWriter(w, crc))
+	buf := make([]byte, binary.MaxVarintLen64)
+	putUvarint := func(x uint64) {
+		b.Write(buf[:binary.PutUvarint(buf, x)])
+	}
+
+	b.WriteString(binaryMagic)
+	b.WriteByte(binaryVersion)
+	b.WriteByte(flags)
+	putUvarint(uint64(count))
+
+	if flags&flagLabels != 0 {
+		g.NodeIter(func(n Node) bool {
+			l := n.Label()
+			putUvarint(uint64(len(l)))
+			b.WriteString(l)
+			return true
+		})
+	}
+
+	type dst struct {
+		index  int
+		weight float32
+	}
+	var dsts []dst
+	g.NodeIter(func(n Node) bool {
+		dsts = dsts[:0]
+		g.NodeEdgeIter(n, func(e Edge) bool {
+			dsts = append(dsts, dst{index: index[e.Dst().ID()], weight: e.Wieght()})
+			return true
+		})
+		sort.Slice(dsts, func(i, j int) bool { return dsts[i].index < dsts[j].index })
+
+		putUvarint(uint64(len(dsts)))
+		prev := 0
+		for _, d := range dsts {
+			putUvarint(uint64(d.index - prev))
+			prev = d.index
+		}
+		if flags&flagWeights != 0 {
+			for _, d := range dsts {
+				binary.LittleEndian.PutUint32(buf, math.Float32bits(d.weight))
+				b.Write(buf[:4])
+			}
+		}
+		return true
+	})
+
+	if err := b.Flush(); err != nil {
+		return err
+	}
+	binary.LittleEndian.PutUint32(buf, crc.Sum32())
+	_, err := w.Write(buf[:4])
+	return err
+}
+
+// ReadBinary reads a graph written by WriteBinary
+func ReadBinary(r io.Reader) (Graph, error) {
+	g := newGraph()
+	if err := g.readBinary(r); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// crcReader computes checksum of the bytes read
+type crcReader struct {
+	r   *bufio.Reader
+	crc hash.Hash32
+	one [1]byte
+}
+
+func (cr *crcReader) Read(p []byte) (int, error) {
+	n, err := io.ReadFull(cr.r, p)
+	cr.crc.Write(p[:n])
+	return n, err
+}
+
+func (cr *crcReader) ReadByte() (byte, error) {
+	c, err := cr.r.ReadByte()
+	if err == nil {
+		cr.one[0] = c
+		cr.crc.Write(cr.one[:])
+	}
+	return c, err
+}
+
+// maxPrealloc limits memory allocated upfront from counts read from the input
+const maxPrealloc = 1 << 16
+
+// maxLabelSize limits the length of a node label read from the input
+const maxLabelSize = 1 << 20
+
+func (g *graph) readBinary(r io.Reader) error {
+	cr := &crcReader{r: bufio.NewReader(r), crc: crc32.New(crcTable)}
+	unexpected := func(err error) error {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+
+	head := make([]byte, len(binaryMagic)+2)
+	if _, err := cr.Read(head); err != nil {
+		return unexpected(err)
+	}
+	if string(head[:len(binaryMagic)]) != binaryMagic {
+		return errors.New("not a binary graph")
+	}
+	if v := head[len(binaryMagic)]; v != binaryVersion {
+		return fmt.Errorf("unsupported binary graph version %d", v)
+	}
+	flags := head[len(binaryMagic)+1]
+
+	count, err := binary.ReadUvarint(cr)
+	if err != nil {
+		return unexpected(err)
+	}
+	if count > math.MaxInt32 {
+		return fmt.Errorf("too many nodes: %d", count)
+	}
+
+	// Nodes and edges are created only after the whole input is read and
+	// its checksum is verified. Every node takes at least one byte of the
+	// input, so memory is bounded by the input size rather than by count.
+	n := int(count)
+	var labels []string
+	if flags&flagLabels != 0 {
+		labels = make([]string, 0, min(n, maxPrealloc))
+		var label []byte
+		for i := 0; i < n; i++ {
+			size, err := binary.ReadUvarint(cr)
+			if err != nil {
+				return unexpected(err)
+			}
+			if size > maxLabelSize {
+				return fmt.Errorf("node #%d: label is too long", i)
+			}
+			if cap(label) < int(size) {
+				label = make([]byte, size)
+			}
+			label = label[:size]
+			if _, err := cr.Read(label); err != nil {
+				return unexpected(err)
+			}
+			labels = append(labels, string(label))
+		}
+	}
+
+	degrees := make([]int, 0, min(n, maxPrealloc))
+	var dsts []int
+	var weights []float32
+	wbuf := make([]byte, 4)
+	for i := 0; i < n; i++ {
+		degree, err := binary.ReadUvarint(cr)
+		if err != nil {
+			return unexpected(err)
+		}
+		if degree > count {
+			return fmt.Errorf("node #%d: wrong degree %d", i, degree)
+		}
+		degrees = append(degrees, int(degree))
+		prev := uint64(0)
+		for j := uint64(0); j < degree; j++ {
+			delta, err := binary.ReadUvarint(cr)
+			if err != nil {
+				return unexpected(err)
+			}
+			prev += delta
+			if delta >= count || prev >= count || j > 0 && delta == 0 {
+				return fmt.Errorf("node #%d: wrong edge destination", i)
+			}
+			dsts = append(dsts, int(prev))
+		}
+		if flags&flagWeights != 0 {
+			for j := uint64(0); j < degree; j++ {
+				if _, err := cr.Read(wbuf); err != nil {
+					return unexpected(err)
+				}
+				weights = append(weights, math.Float32frombits(binary.LittleEndian.Uint32(wbuf)))
+			}
+		}
+	}
+
+	sum := cr.crc.Sum32()
+	if _, err := io.ReadFull(cr.r, wbuf); err != nil {
+		return unexpected(err)
+	}
+	if binary.LittleEndian.Uint32(wbuf) != sum {
+		return ErrBadChecksum
+	}
+
+	nodes := make([]Node, n)
+	for i := range nodes {
+		var l string
+		if labels != nil {
+			l = labels[i]
+		}
+		nd, err := g.NewNode(l)
+		if err != nil {
+			return err
+		}
+		nodes[i] = nd
+	}
+	k := 0
+	for i, src := range nodes {
+		for _, d := range dsts[k : k+degrees[i]] {
+			w := float32(1)
+			if weights != nil {
+				w = weights[k]
+			}
+			g.AddEdge(src, nodes[d], w)
+			k++
+		}
+	}
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (g *graph) MarshalBinary() ([]byte, error) {
+	var b bytes.Buffer
+	if err := WriteBinary(&b, g); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. It replaces the graph content.
+func (g *graph) UnmarshalBinary(data []byte) error {
+	ng := newGraph()
+	if err := ng.readBinary(bytes.NewReader(data)); err != nil {
+		return err
+	}
+	g.replace(ng)
+	return nil
+}
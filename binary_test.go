@@ -0,0 +1,137 @@
+package gorka
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/iimos/gorka/gralang"
+	"github.com/iimos/gorka/internal/graphutil"
+)
+
+func sameGraph(a, b Graph) bool {
+	if a.NodesCount() != b.NodesCount() || a.EdgesCount() != b.EdgesCount() {
+		return false
+	}
+	nodesA, nodesB := []Node{}, []Node{}
+	a.NodeIter(func(n Node) bool { nodesA = append(nodesA, n); return true })
+	b.NodeIter(func(n Node) bool { nodesB = append(nodesB, n); return true })
+
+	for i, n := range nodesA {
+		if n.Label() != nodesB[i].Label() {
+			return false
+		}
+		ea, eb := graphutil.SortedEdges(a, n), graphutil.SortedEdges(b, nodesB[i])
+		if len(ea) != len(eb) {
+			return false
+		}
+		for j := range ea {
+			if ea[j].Dst().Label() != eb[j].Dst().Label() || ea[j].Wieght() != eb[j].Wieght() {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	weighted := New()
+	gralang.Parse(weighted, "a -> b c; c -- d")
+	a, _ := weighted.NodeByLabel("a")
+	b, _ := weighted.NodeByLabel("b")
+	weighted.AddEdge(a, b, -2.5)
+
+	unlabeled, _ := NewRegular(50, 6)
+	random, _ := NewRandom(100, 0.1)
+
+	cases := []Graph{New(), weighted, unlabeled, random}
+	for i, g := range cases {
+		data, err := g.(*graph).MarshalBinary()
+		if err != nil {
+			t.Errorf("#%d: marshal error: %s", i, err)
+			continue
+		}
+		g2 := New()
+		gralang.Parse(g2, "old -> content")
+		if err := g2.(*graph).UnmarshalBinary(data); err != nil {
+			t.Errorf("#%d: unmarshal error: %s", i, err)
+			continue
+		}
+		if !sameGraph(g, g2) {
+			t.Errorf("#%d: graph changed:\n%s\nexpected:\n%s", i, g2, g)
+		}
+
+		g3, err := ReadBinary(bytes.NewReader(data))
+		if err != nil || !sameGraph(g, g3) {
+			t.Errorf("#%d: ReadBinary failed: %v", i, err)
+		}
+	}
+}
+
+func TestBinaryCorrupted(t *testing.T) {
+	g := New()
+	gralang.Parse(g, "a -> b c; c -- d")
+	data, _ := g.(*graph).MarshalBinary()
+
+	if _, err := ReadBinary(bytes.NewReader(data[:len(data)-1])); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("truncated data: %v", err)
+	}
+	if _, err := ReadBinary(bytes.NewReader(nil)); err == nil {
+		t.Errorf("empty data should fail")
+	}
+
+	for i := 6; i < len(data); i++ {
+		bad := append([]byte(nil), data...)
+		bad[i] ^= 0x55
+		if _, err := ReadBinary(bytes.NewReader(bad)); err == nil {
+			t.Errorf("corrupted byte #%d is not detected", i)
+		}
+	}
+
+	bad := append([]byte(nil), data...)
+	bad[4] = 99
+	if _, err := ReadBinary(bytes.NewReader(bad)); err == nil {
+		t.Errorf("unknown version should fail")
+	}
+	bad = append([]byte("XXXX"), data[4:]...)
+	if _, err := ReadBinary(bytes.NewReader(bad)); err == nil {
+		t.Errorf("wrong magic should fail")
+	}
+
+	// huge node counts must not allocate nodes before the data is read
+	for _, flags := range []string{"\x00", "\x01", "\x02"} {
+		header := "GRKB\x01" + flags + "\xff\xff\xff\x1f"
+		if _, err := ReadBinary(strings.NewReader(header)); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("truncated header with flags %q: %v", flags, err)
+		}
+	}
+}
+
+func TestBinarySize(t *testing.T) {
+	g, _ := NewRegular(1000, 10)
+	data, _ := g.(*graph).MarshalBinary()
+	js, _ := json.Marshal(g)
+	if len(data)*5 > len(js) {
+		t.Errorf("binary encoding is not compact enough: %d bytes vs %d bytes of json", len(data), len(js))
+	}
+}
+
+func BenchmarkWriteBinary(b *testing.B) {
+	g, _ := NewRegular(10000, 10)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		WriteBinary(io.Discard, g)
+	}
+}
+
+func BenchmarkReadBinary(b *testing.B) {
+	g, _ := NewRegular(10000, 10)
+	data, _ := g.(*graph).MarshalBinary()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ReadBinary(bytes.NewReader(data))
+	}
+}
@@ -0,0 +1,144 @@
+package gorka
+
+import "github.com/iimos/gorka/internal/graphutil"
+
+// DFSVisitor receives events of DepthFirstSearch. Times are taken from one
+// clock that ticks on every discovery and finish, so for any two nodes their
+// [discover, finish] intervals are either nested or disjoint.
+// Embed NopDFSVisitor to implement only the events of interest.
+type DFSVisitor interface {
+	// DiscoverNode is called when the search enters the node
+	DiscoverNode(n Node, time int)
+	// FinishNode is called when all edges of the node are explored
+	FinishNode(n Node, time int)
+	// TreeEdge is called for an edge to an undiscovered node,
+	// the search continues from that node
+	TreeEdge(e Edge)
+	// BackEdge is called for an edge to a node on the current search path,
+	// including self-loops. Back edges exist only in graphs with cycles.
+	BackEdge(e Edge)
+	// ForwardEdge is called for an edge to a finished descendant
+	ForwardEdge(e Edge)
+	// CrossEdge is called for an edge to a finished node that is not a descendant
+	CrossEdge(e Edge)
+}
+
+// NopDFSVisitor ignores all events
+type NopDFSVisitor struct{}
+
+// DiscoverNode does nothing
+func (NopDFSVisitor) DiscoverNode(n Node, time int) {}
+
+// FinishNode does nothing
+func (NopDFSVisitor) FinishNode(n Node, time int) {}
+
+// TreeEdge does nothing
+func (NopDFSVisitor) TreeEdge(e Edge) {}
+
+// BackEdge does nothing
+func (NopDFSVisitor) BackEdge(e Edge) {}
+
+// ForwardEdge does nothing
+func (NopDFSVisitor) ForwardEdge(e Edge) {}
+
+// CrossEdge does nothing
+func (NopDFSVisitor) CrossEdge(e Edge) {}
+
+// DFSTimes holds discovery and finish times of a depth-first search
+type DFSTimes struct {
+	discover []int
+	finish   []int
+}
+
+// Discovered returns the discovery time of the node, 0 if it was not reached
+func (t *DFSTimes) Discovered(n Node) int {
+	if n.ID() >= len(t.discover) {
+		return 0
+	}
+	return t.discover[n.ID()]
+}
+
+// Finished returns the finish time of the node, 0 if it was not reached
+func (t *DFSTimes) Finished(n Node) int {
+	if n.ID() >= len(t.finish) {
+		return 0
+	}
+	return t.finish[n.ID()]
+}
+
+// DepthFirstSearch explores the graph over outgoing edges in depth-first
+// order, reporting events to the visitor, which may be nil. The search starts
+// from every root in turn, skipping already discovered ones; nil roots mean
+// all nodes in NodeIter order, so that the whole graph is covered by a forest
+// of search trees. Edges of a node are explored in the order of destination IDs.
+// Unlike TraverseDepthFirst a node is finished only after all its descendants,
+// which gives the times and edge types needed for cycle detection,
+// topological sorting and strongly connected components.
+func DepthFirstSearch(g Graph, roots []Node, v DFSVisitor) *DFSTimes {
+	if v == nil {
+		v = NopDFSVisitor{}
+	}
+	size := g.MaxNodeID() + 1
+	t := &DFSTimes{
+		discover: make([]int, size),
+		finish:   make([]int, size),
+	}
+	clock := 0
+
+	type frame struct {
+		node  Node
+		edges []Edge
+		next  int
+	}
+	var stack []frame
+	discover := func(n Node) {
+		clock++
+		t.discover[n.ID()] = clock
+		v.DiscoverNode(n, clock)
+		stack = append(stack, frame{node: n, edges: graphutil.SortedEdges(g, n)})
+	}
+
+	search := func(root Node) {
+		if t.discover[root.ID()] != 0 {
+			return
+		}
+		discover(root)
+		for len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			if top.next == len(top.edges) {
+				clock++
+				t.finish[top.node.ID()] = clock
+				v.FinishNode(top.node, clock)
+				stack = stack[:len(stack)-1]
+				continue
+			}
+			e := top.edges[top.next]
+			top.next++
+
+			u, d := top.node.ID(), e.Dst().ID()
+			switch {
+			case t.discover[d] == 0:
+				v.TreeEdge(e)
+				discover(e.Dst())
+			case t.finish[d] == 0:
+				v.BackEdge(e)
+			case t.discover[u] < t.discover[d]:
+				v.ForwardEdge(e)
+			default:
+				v.CrossEdge(e)
+			}
+		}
+	}
+
+	if roots == nil {
+		g.NodeIter(func(n Node) bool {
+			search(n)
+			return true
+		})
+	} else {
+		for _, n := range roots {
+			search(n)
+		}
+	}
+	return t
+}
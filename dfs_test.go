@@ -0,0 +1,121 @@
+package gorka
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/iimos/gorka/gralang"
+)
+
+type recordingVisitor struct {
+	events []string
+}
+
+func (r *recordingVisitor) add(kind, s string) { r.events = append(r.events, kind+" "+s) }
+
+func (r *recordingVisitor) DiscoverNode(n Node, time int) { r.add("discover", n.Label()) }
+func (r *recordingVisitor) FinishNode(n Node, time int)   { r.add("finish", n.Label()) }
+func (r *recordingVisitor) TreeEdge(e Edge)               { r.add("tree", e.From().Label()+e.Dst().Label()) }
+func (r *recordingVisitor) BackEdge(e Edge)               { r.add("back", e.From().Label()+e.Dst().Label()) }
+func (r *recordingVisitor) ForwardEdge(e Edge)            { r.add("forward", e.From().Label()+e.Dst().Label()) }
+func (r *recordingVisitor) CrossEdge(e Edge)              { r.add("cross", e.From().Label()+e.Dst().Label()) }
+
+func TestDepthFirstSearch(t *testing.T) {
+	type tcase struct {
+		s      string
+		events string
+	}
+	cases := [...]tcase{
+		tcase{"", ""},
+		tcase{"a -> b -> c",
+			"discover a, tree ab, discover b, tree bc, discover c, finish c, finish b, finish a"},
+		tcase{"a -> b -> c -> a",
+			"discover a, tree ab, discover b, tree bc, discover c, back ca, finish c, finish b, finish a"},
+		tcase{"a -> b c; b -> c",
+			"discover a, tree ab, discover b, tree bc, discover c, finish c, finish b, forward ac, finish a"},
+		tcase{"a -> b; c -> b",
+			"discover a, tree ab, discover b, finish b, finish a, discover c, cross cb, finish c"},
+		tcase{"a b",
+			"discover a, finish a, discover b, finish b"},
+	}
+
+	for i, c := range cases {
+		g := New()
+		gralang.Parse(g, c.s)
+		r := &recordingVisitor{}
+		DepthFirstSearch(g, nil, r)
+		if res := strings.Join(r.events, ", "); res != c.events {
+			t.Errorf("#%d: wrong events:\n%s\nexpected:\n%s", i, res, c.events)
+		}
+	}
+}
+
+func TestDepthFirstSearchSelfLoop(t *testing.T) {
+	g := New()
+	a, _ := g.NewNode("a")
+	g.AddEdge(a, a, 1)
+	r := &recordingVisitor{}
+	DepthFirstSearch(g, nil, r)
+	if res := strings.Join(r.events, ", "); res != "discover a, back aa, finish a" {
+		t.Errorf("wrong events: %s", res)
+	}
+}
+
+type topoVisitor struct {
+	NopDFSVisitor
+	order  []string
+	cyclic bool
+}
+
+func (v *topoVisitor) FinishNode(n Node, time int) { v.order = append([]string{n.Label()}, v.order...) }
+func (v *topoVisitor) BackEdge(e Edge)             { v.cyclic = true }
+
+func TestDepthFirstSearchTimes(t *testing.T) {
+	g := New()
+	gralang.Parse(g, "shirt -> tie belt; tie -> jacket; belt -> jacket; pants -> shoes belt; socks -> shoes; x")
+	v := &topoVisitor{}
+	times := DepthFirstSearch(g, nil, v)
+	if v.cyclic {
+		t.Errorf("acyclic graph has back edges")
+	}
+
+	pos := map[string]int{}
+	for i, l := range v.order {
+		pos[l] = i
+	}
+	g.NodeIter(func(n Node) bool {
+		d, f := times.Discovered(n), times.Finished(n)
+		if d == 0 || f <= d || f > 2*g.NodesCount() {
+			t.Errorf("%s: wrong times %d %d", n, d, f)
+		}
+		g.NodeEdgeIter(n, func(e Edge) bool {
+			if pos[n.Label()] > pos[e.Dst().Label()] {
+				t.Errorf("wrong topological order %v", v.order)
+			}
+			return true
+		})
+		return true
+	})
+
+	// intervals are nested or disjoint
+	nodes := []Node{}
+	g.NodeIter(func(n Node) bool { nodes = append(nodes, n); return true })
+	sort.Slice(nodes, func(i, j int) bool { return times.Discovered(nodes[i]) < times.Discovered(nodes[j]) })
+	for i := 0; i < len(nodes); i++ {
+		for j := i + 1; j < len(nodes); j++ {
+			a, b := nodes[i], nodes[j]
+			if times.Discovered(b) < times.Finished(a) && times.Finished(b) > times.Finished(a) {
+				t.Errorf("intervals of %s and %s overlap", a, b)
+			}
+		}
+	}
+
+	// search from given roots only
+	tie, _ := g.NodeByLabel("tie")
+	socks, _ := g.NodeByLabel("socks")
+	times = DepthFirstSearch(g, []Node{tie}, nil)
+	if times.Discovered(tie) != 1 || times.Discovered(socks) != 0 {
+		t.Errorf("wrong times for search from tie")
+	}
+}
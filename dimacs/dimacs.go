@@ -0,0 +1,263 @@
+package dimacs
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/iimos/gorka/internal/graphutil"
+	"github.com/iimos/gorka/types"
+)
+
+// Problem is the problem line of a DIMACS file and its designated nodes.
+// Type is "sp" for shortest paths (.gr), "max" for maximum flow
+// and "edge" for undirected graphs (.col).
+type Problem struct {
+	Type   string
+	Nodes  int
+	Arcs   int
+	Source types.Node // source of a max flow problem
+	Sink   types.Node // sink of a max flow problem
+}
+
+// Options controls writing
+type Options struct {
+	// Type of the problem line, default is "sp"
+	Type string
+	// Comment is written as comment lines at the top of the file
+	Comment string
+	// Source and Sink are written as designated nodes of a max flow problem
+	Source types.Node
+	Sink   types.Node
+}
+
+// maxNodes limits the nodes count of the problem line, so that a short
+// file can't make Read create nodes until memory runs out
+const maxNodes = 1 << 26
+
+// Read reads a graph in DIMACS format. Nodes are numbered from 1 in the file
+// and get their numbers as labels. Arcs ("a u v w") are added as edges of
+// weight w, in max flow problems the weight is the arc capacity. Edges of
+// "edge" problems ("e u v") are added in both directions with weight 1.
+// Problems of more than 1<<26 nodes are rejected.
+func Read(g types.Graph, r io.Reader) (*Problem, error) {
+	if g == nil {
+		return nil, errors.New("graph is empty")
+	}
+
+	scan := bufio.NewScanner(r)
+	line := 0
+	errorf := func(format string, args ...interface{}) error {
+		return fmt.Errorf("line %d: %s", line, fmt.Sprintf(format, args...))
+	}
+
+	var p *Problem
+	var nodes []types.Node
+	node := func(s string) (types.Node, error) {
+		i, err := strconv.Atoi(s)
+		if err != nil || i < 1 || i > len(nodes) {
+			return nil, errorf("wrong node '%s'", s)
+		}
+		return nodes[i-1], nil
+	}
+
+	count := 0
+	for scan.Scan() {
+		line++
+		fields := strings.Fields(scan.Text())
+		if len(fields) == 0 || fields[0] == "c" {
+			continue
+		}
+		if fields[0] != "p" && p == nil {
+			return nil, errorf("problem line is missing")
+		}
+
+		switch fields[0] {
+		case "p":
+			if p != nil {
+				return nil, errorf("duplicate problem line")
+			}
+			if len(fields) != 4 {
+				return nil, errorf("expected 'p type nodes arcs'")
+			}
+			p = &Problem{Type: fields[1]}
+			var err error
+			if p.Nodes, err = strconv.Atoi(fields[2]); err != nil || p.Nodes < 0 {
+				return nil, errorf("wrong nodes count '%s'", fields[2])
+			}
+			if p.Nodes > maxNodes {
+				return nil, errorf("nodes count %d is over the limit %d", p.Nodes, maxNodes)
+			}
+			if p.Arcs, err = strconv.Atoi(fields[3]); err != nil || p.Arcs < 0 {
+				return nil, errorf("wrong arcs count '%s'", fields[3])
+			}
+			nodes = make([]types.Node, p.Nodes)
+			for i := range nodes {
+				nodes[i] = graphutil.Obtain(g, strconv.Itoa(i+1))
+			}
+
+		case "n":
+			// designated node of a flow problem: n id s|t
+			if len(fields) != 3 {
+				return nil, errorf("expected 'n node s|t'")
+			}
+			n, err := node(fields[1])
+			if err != nil {
+				return nil, err
+			}
+			switch fields[2] {
+			case "s":
+				p.Source = n
+			case "t":
+				p.Sink = n
+			default:
+				return nil, errorf("wrong node designation '%s'", fields[2])
+			}
+
+		case "a":
+			if len(fields) != 3 && len(fields) != 4 {
+				return nil, errorf("expected 'a from to [weight]'")
+			}
+			src, err := node(fields[1])
+			if err != nil {
+				return nil, err
+			}
+			dst, err := node(fields[2])
+			if err != nil {
+				return nil, err
+			}
+			w := float32(1)
+			if len(fields) == 4 {
+				f, err := strconv.ParseFloat(fields[3], 32)
+				if err != nil {
+					return nil, errorf("wrong weight '%s'", fields[3])
+				}
+				w = float32(f)
+			}
+			g.AddEdge(src, dst, w)
+			count++
+
+		case "e":
+			if len(fields) != 3 {
+				return nil, errorf("expected 'e node node'")
+			}
+			a, err := node(fields[1])
+			if err != nil {
+				return nil, err
+			}
+			b, err := node(fields[2])
+			if err != nil {
+				return nil, err
+			}
+			g.AddBiEdge(a, b, 1)
+			count++
+
+		default:
+			return nil, errorf("unknown line type '%s'", fields[0])
+		}
+	}
+	if err := scan.Err(); err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, errors.New("problem line is missing")
+	}
+	if count != p.Arcs {
+		return nil, fmt.Errorf("expected %d arcs, got %d", p.Arcs, count)
+	}
+	return p, nil
+}
+
+// ReadFile reads a DIMACS file, gzipped files are detected by .gz extension
+func ReadFile(g types.Graph, name string) (*Problem, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(name, ".gz") {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err)
+		}
+		r = zr
+	}
+	p, err := Read(g, r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", name, err)
+	}
+	return p, nil
+}
+
+// Write writes the graph in DIMACS format. Nodes are numbered from 1 in
+// NodeIter order. For "edge" problems opposite edges are merged as
+// graphutil.SkipOpposite describes.
+func Write(w io.Writer, g types.Graph, opt *Options) error {
+	if opt == nil {
+		opt = &Options{}
+	}
+	typ := opt.Type
+	if typ == "" {
+		typ = "sp"
+	}
+	undirected := typ == "edge"
+
+	index := make([]int, g.MaxNodeID()+1)
+	count := 0
+	g.NodeIter(func(n types.Node) bool {
+		count++
+		index[n.ID()] = count
+		return true
+	})
+	arcs := 0
+	g.NodeIter(func(n types.Node) bool {
+		g.NodeEdgeIter(n, func(e types.Edge) bool {
+			if !undirected || !graphutil.SkipOpposite(g, n, e.Dst()) {
+				arcs++
+			}
+			return true
+		})
+		return true
+	})
+
+	b := bufio.NewWriter(w)
+	if opt.Comment != "" {
+		for _, l := range strings.Split(opt.Comment, "\n") {
+			b.WriteString("c ")
+			b.WriteString(l)
+			b.WriteByte('\n')
+		}
+	}
+	fmt.Fprintf(b, "p %s %d %d\n", typ, count, arcs)
+	if opt.Source != nil {
+		fmt.Fprintf(b, "n %d s\n", index[opt.Source.ID()])
+	}
+	if opt.Sink != nil {
+		fmt.Fprintf(b, "n %d t\n", index[opt.Sink.ID()])
+	}
+
+	g.NodeIter(func(n types.Node) bool {
+		for _, e := range graphutil.SortedEdges(g, n) {
+			src, dst := strconv.Itoa(index[n.ID()]), strconv.Itoa(index[e.Dst().ID()])
+			if undirected {
+				if graphutil.SkipOpposite(g, n, e.Dst()) {
+					continue
+				}
+				b.WriteString("e " + src + " " + dst + "\n")
+				continue
+			}
+			b.WriteString("a " + src + " " + dst + " ")
+			b.WriteString(strconv.FormatFloat(float64(e.Wieght()), 'g', -1, 32))
+			b.WriteByte('\n')
+		}
+		return true
+	})
+	return b.Flush()
+}
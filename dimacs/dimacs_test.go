@@ -0,0 +1,176 @@
+package dimacs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iimos/gorka"
+	"github.com/iimos/gorka/internal/graphtest"
+)
+
+const sp = `c 9th DIMACS Implementation Challenge: Shortest Paths
+c
+p sp 4 5
+c graph contains 4 nodes and 5 arcs
+a 1 2 7
+a 1 3 2
+a 3 2 3
+a 2 4 1
+a 3 4 12
+`
+
+func TestRead(t *testing.T) {
+	type e struct {
+		a, b string
+		w    float32
+	}
+	type tcase struct {
+		text      string
+		typ       string
+		nodeCount int
+		edgeCount int
+		source    string
+		sink      string
+		edges     []e
+	}
+	cases := []tcase{
+		tcase{sp, "sp", 4, 5, "", "", []e{{"1", "2", 7}, {"3", "2", 3}, {"3", "4", 12}}},
+		tcase{"c max flow\np max 3 2\nn 1 s\nn 3 t\na 1 2 4\na 2 3 1.5\n", "max", 3, 2, "1", "3",
+			[]e{{"1", "2", 4}, {"2", "3", 1.5}}},
+		tcase{"p edge 3 2\ne 1 2\ne 2 3\n", "edge", 3, 4, "", "", []e{{"1", "2", 1}, {"2", "1", 1}, {"3", "2", 1}}},
+		tcase{"p sp 5 0\n", "sp", 5, 0, "", "", nil},
+	}
+	for i, c := range cases {
+		g := gorka.New()
+		p, err := Read(g, strings.NewReader(c.text))
+		if err != nil {
+			t.Errorf("#%d: %s", i, err)
+			continue
+		}
+		if p.Type != c.typ {
+			t.Errorf("#%d: expected problem type %s, got %s", i, c.typ, p.Type)
+		}
+		if g.NodesCount() != c.nodeCount {
+			t.Errorf("#%d: expected %d nodes, got %d", i, c.nodeCount, g.NodesCount())
+		}
+		if g.EdgesCount() != c.edgeCount {
+			t.Errorf("#%d: expected %d edges, got %d", i, c.edgeCount, g.EdgesCount())
+		}
+		if c.source != "" && (p.Source == nil || p.Source.Label() != c.source) {
+			t.Errorf("#%d: expected source %s, got %v", i, c.source, p.Source)
+		}
+		if c.sink != "" && (p.Sink == nil || p.Sink.Label() != c.sink) {
+			t.Errorf("#%d: expected sink %s, got %v", i, c.sink, p.Sink)
+		}
+		for _, edge := range c.edges {
+			if w, ok := graphtest.Weight(g, edge.a, edge.b); !ok || w != edge.w {
+				t.Errorf("#%d: expected edge %s -> %s of weight %v", i, edge.a, edge.b, edge.w)
+			}
+		}
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	cases := []string{
+		"",
+		"c only comments\n",
+		"a 1 2 3\n",
+		"p sp 2\n",
+		"p sp x 1\n",
+		"p sp 2 -1\n",
+		"p sp 2 1\np sp 2 1\n",
+		"p sp 2 1\na 1 3 1\n",
+		"p sp 2 1\na 0 1 1\n",
+		"p sp 2 1\na 1 2 x\n",
+		"p sp 2 1\na 1\n",
+		"p sp 2 2\na 1 2 1\n",
+		"p sp 2 0\na 1 2 1\n",
+		"p max 2 0\nn 1 x\n",
+		"p max 2 0\nn 5 s\n",
+		"p edge 2 1\ne 1\n",
+		"p sp 2 0\nv 1 2 3\n",
+		"p sp 2000000000 0\n",
+	}
+	for i, c := range cases {
+		if _, err := Read(gorka.New(), strings.NewReader(c)); err == nil {
+			t.Errorf("#%d: expected error", i)
+		}
+	}
+	if _, err := Read(nil, strings.NewReader(sp)); err == nil {
+		t.Errorf("expected error on nil graph")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	g := gorka.New()
+	a, _ := g.NewNode("a")
+	b, _ := g.NewNode("b")
+	c, _ := g.NewNode("c")
+	g.AddEdge(a, c, 2)
+	g.AddEdge(a, b, 1)
+	g.AddBiEdge(b, c, 0.5)
+
+	var tests = []struct {
+		opt  *Options
+		want string
+	}{
+		{nil, "p sp 3 4\na 1 2 1\na 1 3 2\na 2 3 0.5\na 3 2 0.5\n"},
+		{&Options{Type: "max", Comment: "flow\nnetwork", Source: a, Sink: c},
+			"c flow\nc network\np max 3 4\nn 1 s\nn 3 t\na 1 2 1\na 1 3 2\na 2 3 0.5\na 3 2 0.5\n"},
+		{&Options{Type: "edge"}, "p edge 3 3\ne 1 2\ne 1 3\ne 2 3\n"},
+	}
+	for i, test := range tests {
+		var buf bytes.Buffer
+		if err := Write(&buf, g, test.opt); err != nil {
+			t.Errorf("#%d: %s", i, err)
+			continue
+		}
+		if buf.String() != test.want {
+			t.Errorf("#%d: expected\n%s\ngot\n%s", i, test.want, buf.String())
+		}
+		if _, err := Read(gorka.New(), &buf); err != nil {
+			t.Errorf("#%d: can't read written graph: %s", i, err)
+		}
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "sample.gr.gz")
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := gzip.NewWriter(f)
+	zw.Write([]byte(sp))
+	zw.Close()
+	f.Close()
+
+	g := gorka.New()
+	p, err := ReadFile(g, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Nodes != 4 || p.Arcs != 5 || g.EdgesCount() != 5 {
+		t.Errorf("unexpected problem %+v with %d edges", p, g.EdgesCount())
+	}
+}
+
+func TestShortestPath(t *testing.T) {
+	g := gorka.New()
+	if _, err := Read(g, strings.NewReader(sp)); err != nil {
+		t.Fatal(err)
+	}
+	a, _ := g.NodeByLabel("1")
+	b, _ := g.NodeByLabel("4")
+	_, l, err := gorka.ShortestPath(g, a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l != 6 {
+		t.Errorf("expected shortest path of length 6, got %v", l)
+	}
+}
@@ -0,0 +1,197 @@
+package dot
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	tokEOF = iota
+	tokID
+	tokPunct
+)
+
+type token struct {
+	kind   int
+	s      string
+	quoted bool // quoted and HTML strings are never keywords
+	line   int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of input"
+	}
+	return "'" + t.s + "'"
+}
+
+type lexer struct {
+	s    string
+	pos  int
+	line int
+}
+
+func (l *lexer) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", l.line, fmt.Sprintf(format, args...))
+}
+
+// skip skips spaces and comments
+func (l *lexer) skip() {
+	for l.pos < len(l.s) {
+		ch := l.s[l.pos]
+		switch {
+		case ch == '\n':
+			l.line++
+			l.pos++
+		case ch == ' ' || ch == '\t' || ch == '\r' || ch == '\f' || ch == '\v':
+			l.pos++
+		case ch == '#' && l.atLineStart():
+			// preprocessor output line
+			l.skipTo("\n")
+		case strings.HasPrefix(l.s[l.pos:], "//"):
+			l.skipTo("\n")
+		case strings.HasPrefix(l.s[l.pos:], "/*"):
+			l.pos += 2
+			l.skipTo("*/")
+			l.pos += 2
+			if l.pos > len(l.s) {
+				l.pos = len(l.s)
+			}
+		default:
+			return
+		}
+	}
+}
+
+// atLineStart tells whether there are only spaces before the current position on its line
+func (l *lexer) atLineStart() bool {
+	start := strings.LastIndexByte(l.s[:l.pos], '\n') + 1
+	return strings.TrimLeft(l.s[start:l.pos], " \t\r\f\v") == ""
+}
+
+// skipTo moves to the next occurrence of sep or to the end of input
+func (l *lexer) skipTo(sep string) {
+	i := strings.Index(l.s[l.pos:], sep)
+	if i < 0 {
+		i = len(l.s) - l.pos
+	}
+	l.line += strings.Count(l.s[l.pos:l.pos+i], "\n")
+	l.pos += i
+}
+
+func (l *lexer) next() (token, error) {
+	l.skip()
+	if l.pos >= len(l.s) {
+		return token{kind: tokEOF, line: l.line}, nil
+	}
+
+	line := l.line
+	rest := l.s[l.pos:]
+	ch := rest[0]
+
+	switch {
+	case strings.HasPrefix(rest, "->") || strings.HasPrefix(rest, "--"):
+		l.pos += 2
+		return token{kind: tokPunct, s: rest[:2], line: line}, nil
+	case strings.IndexByte("{}[];,=:", ch) >= 0:
+		l.pos++
+		return token{kind: tokPunct, s: rest[:1], line: line}, nil
+	case ch == '"':
+		s, err := l.quoted()
+		return token{kind: tokID, s: s, quoted: true, line: line}, err
+	case ch == '<':
+		s, err := l.html()
+		return token{kind: tokID, s: s, quoted: true, line: line}, err
+	case ch == '-' || ch == '.' || ch >= '0' && ch <= '9':
+		i := 1
+		for i < len(rest) && (rest[i] == '.' || rest[i] >= '0' && rest[i] <= '9') {
+			i++
+		}
+		if !isNumeral(rest[:i]) {
+			return token{}, l.errorf("wrong numeral '%s'", rest[:i])
+		}
+		l.pos += i
+		return token{kind: tokID, s: rest[:i], line: line}, nil
+	case ch == '_' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= 0x80:
+		i := 1
+		for i < len(rest) && (rest[i] == '_' || rest[i] >= 'a' && rest[i] <= 'z' || rest[i] >= 'A' && rest[i] <= 'Z' ||
+			rest[i] >= '0' && rest[i] <= '9' || rest[i] >= 0x80) {
+			i++
+		}
+		l.pos += i
+		return token{kind: tokID, s: rest[:i], line: line}, nil
+	}
+	return token{}, l.errorf("unexpected character '%c'", ch)
+}
+
+// quoted reads a double-quoted string, strings joined with '+' are concatenated
+func (l *lexer) quoted() (string, error) {
+	var b strings.Builder
+	for {
+		start := l.line
+		l.pos++ // opening quote
+		for {
+			if l.pos >= len(l.s) {
+				l.line = start
+				return "", l.errorf("unterminated string")
+			}
+			ch := l.s[l.pos]
+			if ch == '"' {
+				l.pos++
+				break
+			}
+			if ch == '\\' && l.pos+1 < len(l.s) {
+				switch next := l.s[l.pos+1]; next {
+				case '"', '\\':
+					b.WriteByte(next)
+					l.pos += 2
+					continue
+				case '\n':
+					// line continuation
+					l.line++
+					l.pos += 2
+					continue
+				}
+			}
+			if ch == '\n' {
+				l.line++
+			}
+			b.WriteByte(ch)
+			l.pos++
+		}
+
+		// "a" + "b"
+		save, saveLine := l.pos, l.line
+		l.skip()
+		if l.pos < len(l.s) && l.s[l.pos] == '+' {
+			l.pos++
+			l.skip()
+			if l.pos < len(l.s) && l.s[l.pos] == '"' {
+				continue
+			}
+			return "", l.errorf("expected string after '+'")
+		}
+		l.pos, l.line = save, saveLine
+		return b.String(), nil
+	}
+}
+
+// html reads an HTML string `<...>` with balanced angle brackets
+func (l *lexer) html() (string, error) {
+	depth := 0
+	for i := l.pos; i < len(l.s); i++ {
+		switch l.s[i] {
+		case '<':
+			depth++
+		case '>':
+			depth--
+			if depth == 0 {
+				s := l.s[l.pos+1 : i]
+				l.line += strings.Count(s, "\n")
+				l.pos = i + 1
+				return s, nil
+			}
+		}
+	}
+	return "", l.errorf("unterminated HTML string")
+}
@@ -0,0 +1,443 @@
+package dot
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/iimos/gorka/internal/graphutil"
+	"github.com/iimos/gorka/types"
+)
+
+// Header describes the graph statement of a DOT document
+type Header struct {
+	Strict   bool
+	Directed bool
+	Name     string
+	Attrs    Attrs // graph attributes of the top level
+}
+
+// Decoder reads a DOT document into a Graph.
+//
+// Supported are node, edge and attribute statements, attribute lists,
+// subgraphs (also as edge endpoints), ports (ignored), comments and
+// strict graphs. Node IDs become node labels, \" and \\ in quoted strings
+// stand for a quote and a backslash. Edge weight is taken from
+// the `weight` attribute or else from a numeric `label`, otherwise it is 1.
+// Undirected edges are added in both directions.
+type Decoder struct {
+	r      io.Reader
+	header Header
+	onNode func(n types.Node, attrs Attrs)
+	onEdge func(e types.Edge, attrs Attrs)
+}
+
+// NewDecoder returns a decoder that reads from r
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r}
+}
+
+// OnNode sets fn to be called after decoding for each node which has attributes
+func (d *Decoder) OnNode(fn func(n types.Node, attrs Attrs)) {
+	d.onNode = fn
+}
+
+// OnEdge sets fn to be called for each edge statement with its attributes.
+// For undirected graphs it gets the edge in the direction it is written.
+func (d *Decoder) OnEdge(fn func(e types.Edge, attrs Attrs)) {
+	d.onEdge = fn
+}
+
+// Header returns the graph header of the decoded document
+func (d *Decoder) Header() Header {
+	return d.header
+}
+
+// Decode reads the document and fills the graph
+func (d *Decoder) Decode(g types.Graph) error {
+	if g == nil {
+		return errors.New("graph is empty")
+	}
+	data, err := io.ReadAll(d.r)
+	if err != nil {
+		return err
+	}
+
+	p := &parser{
+		lex:       lexer{s: string(data), line: 1},
+		g:         g,
+		nodeAttrs: make(map[string]Attrs),
+		onEdge:    d.onEdge,
+	}
+	p.header.Attrs = Attrs{}
+	if err := p.parse(); err != nil {
+		return err
+	}
+	d.header = p.header
+
+	if d.onNode != nil {
+		for _, l := range p.nodeOrder {
+			if attrs := p.nodeAttrs[l]; len(attrs) > 0 {
+				n, _ := g.NodeByLabel(l)
+				d.onNode(n, attrs)
+			}
+		}
+	}
+	return nil
+}
+
+// Parse parses DOT document and fills the graph
+func Parse(g types.Graph, s string) error {
+	return NewDecoder(strings.NewReader(s)).Decode(g)
+}
+
+type parser struct {
+	lex    lexer
+	tok    token
+	g      types.Graph
+	header Header
+
+	nodeDefaults Attrs
+	edgeDefaults Attrs
+	nodeAttrs    map[string]Attrs
+	nodeOrder    []string
+	onEdge       func(e types.Edge, attrs Attrs)
+}
+
+func (p *parser) next() error {
+	t, err := p.lex.next()
+	p.tok = t
+	return err
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", p.tok.line, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) expect(punct string) error {
+	if p.tok.kind != tokPunct || p.tok.s != punct {
+		return p.errorf("expected '%s', got %s", punct, p.tok)
+	}
+	return p.next()
+}
+
+func (p *parser) isPunct(punct string) bool {
+	return p.tok.kind == tokPunct && p.tok.s == punct
+}
+
+func (p *parser) isKeyword(kw string) bool {
+	return p.tok.kind == tokID && !p.tok.quoted && strings.EqualFold(p.tok.s, kw)
+}
+
+// parse reads `[strict] (graph|digraph) [ID] { stmt_list }`
+func (p *parser) parse() error {
+	if err := p.next(); err != nil {
+		return err
+	}
+	if p.isKeyword("strict") {
+		p.header.Strict = true
+		if err := p.next(); err != nil {
+			return err
+		}
+	}
+	switch {
+	case p.isKeyword("digraph"):
+		p.header.Directed = true
+	case p.isKeyword("graph"):
+	default:
+		return p.errorf("expected 'graph' or 'digraph', got %s", p.tok)
+	}
+	if err := p.next(); err != nil {
+		return err
+	}
+	if p.tok.kind == tokID {
+		p.header.Name = p.tok.s
+		if err := p.next(); err != nil {
+			return err
+		}
+	}
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	if _, err := p.stmtList(true); err != nil {
+		return err
+	}
+	if err := p.expect("}"); err != nil {
+		return err
+	}
+	if p.tok.kind != tokEOF {
+		return p.errorf("unexpected %s after the graph", p.tok)
+	}
+	return nil
+}
+
+// stmtList reads statements until '}' and returns labels of all nodes met
+func (p *parser) stmtList(top bool) ([]string, error) {
+	var nodes []string
+	for !p.isPunct("}") {
+		if p.tok.kind == tokEOF {
+			return nil, p.errorf("unexpected end of input")
+		}
+		stmtNodes, err := p.stmt(top)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, stmtNodes...)
+		if p.isPunct(";") {
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return nodes, nil
+}
+
+func (p *parser) stmt(top bool) ([]string, error) {
+	switch {
+	case p.isKeyword("graph"), p.isKeyword("node"), p.isKeyword("edge"):
+		kind := strings.ToLower(p.tok.s)
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		attrs, err := p.attrLists()
+		if err != nil {
+			return nil, err
+		}
+		switch kind {
+		case "graph":
+			if top {
+				merge(p.header.Attrs, attrs)
+			}
+		case "node":
+			p.nodeDefaults = merged(p.nodeDefaults, attrs)
+		case "edge":
+			p.edgeDefaults = merged(p.edgeDefaults, attrs)
+		}
+		return nil, nil
+	}
+
+	if p.tok.kind == tokID && !p.isKeyword("subgraph") {
+		id := p.tok
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		if p.isPunct("=") {
+			// ID '=' ID sets graph attribute
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			if p.tok.kind != tokID {
+				return nil, p.errorf("expected attribute value, got %s", p.tok)
+			}
+			if top {
+				p.header.Attrs[id.s] = p.tok.s
+			}
+			return nil, p.next()
+		}
+		if err := p.port(); err != nil {
+			return nil, err
+		}
+		return p.edgeStmt([]string{id.s}, true)
+	}
+
+	nodes, err := p.subgraph()
+	if err != nil {
+		return nil, err
+	}
+	return p.edgeStmt(nodes, false)
+}
+
+// edgeStmt reads the rest of a node or edge statement which first operand is already read
+func (p *parser) edgeStmt(first []string, single bool) ([]string, error) {
+	operands := [][]string{first}
+	all := append([]string(nil), first...)
+
+	for p.isPunct("->") || p.isPunct("--") {
+		if p.header.Directed != (p.tok.s == "->") {
+			return nil, p.errorf("edge '%s' doesn't match the graph type", p.tok.s)
+		}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+
+		var nodes []string
+		if p.tok.kind == tokID && !p.isKeyword("subgraph") {
+			nodes = []string{p.tok.s}
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			if err := p.port(); err != nil {
+				return nil, err
+			}
+		} else if p.isPunct("{") || p.isKeyword("subgraph") {
+			var err error
+			if nodes, err = p.subgraph(); err != nil {
+				return nil, err
+			}
+		} else {
+			return nil, p.errorf("expected node or subgraph, got %s", p.tok)
+		}
+		operands = append(operands, nodes)
+		all = append(all, nodes...)
+	}
+
+	attrs, err := p.attrLists()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(operands) == 1 {
+		if single {
+			p.node(first[0], attrs)
+		}
+		return all, nil
+	}
+
+	for _, l := range all {
+		p.node(l, nil)
+	}
+
+	attrs = merged(p.edgeDefaults, attrs)
+	weight := edgeWeight(attrs)
+	for i := 1; i < len(operands); i++ {
+		for _, l := range operands[i-1] {
+			src := p.node(l, nil)
+			for _, r := range operands[i] {
+				dst := p.node(r, nil)
+				e := p.g.AddEdge(src, dst, weight)
+				if !p.header.Directed {
+					p.g.AddEdge(dst, src, weight)
+				}
+				if p.onEdge != nil {
+					p.onEdge(e, attrs)
+				}
+			}
+		}
+	}
+	return all, nil
+}
+
+// subgraph reads `[subgraph [ID]] { stmt_list }` and returns labels of its nodes
+func (p *parser) subgraph() ([]string, error) {
+	if p.isKeyword("subgraph") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind == tokID {
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	// default attributes set inside a subgraph don't leak out of it
+	nodeDefaults, edgeDefaults := p.nodeDefaults, p.edgeDefaults
+	nodes, err := p.stmtList(false)
+	if err != nil {
+		return nil, err
+	}
+	p.nodeDefaults, p.edgeDefaults = nodeDefaults, edgeDefaults
+
+	return nodes, p.expect("}")
+}
+
+// port skips `:ID[:compass_pt]` after node ID
+func (p *parser) port() error {
+	for i := 0; i < 2 && p.isPunct(":"); i++ {
+		if err := p.next(); err != nil {
+			return err
+		}
+		if p.tok.kind != tokID {
+			return p.errorf("expected port, got %s", p.tok)
+		}
+		if err := p.next(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attrLists reads `[ a=b, c=d ][ e=f ]...`
+func (p *parser) attrLists() (Attrs, error) {
+	var attrs Attrs
+	for p.isPunct("[") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		if attrs == nil {
+			attrs = Attrs{}
+		}
+		for !p.isPunct("]") {
+			if p.tok.kind != tokID {
+				return nil, p.errorf("expected attribute name, got %s", p.tok)
+			}
+			key := p.tok.s
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			if err := p.expect("="); err != nil {
+				return nil, err
+			}
+			if p.tok.kind != tokID {
+				return nil, p.errorf("expected attribute value, got %s", p.tok)
+			}
+			attrs[key] = p.tok.s
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			if p.isPunct(",") || p.isPunct(";") {
+				if err := p.next(); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+	}
+	return attrs, nil
+}
+
+// node returns the node with the label creating it if needed and merges attrs into its attributes
+func (p *parser) node(label string, attrs Attrs) types.Node {
+	n := graphutil.Obtain(p.g, label)
+	cur, seen := p.nodeAttrs[label]
+	if !seen {
+		cur = merged(p.nodeDefaults, nil)
+		p.nodeAttrs[label] = cur
+		p.nodeOrder = append(p.nodeOrder, label)
+	}
+	merge(cur, attrs)
+	return n
+}
+
+func edgeWeight(attrs Attrs) float32 {
+	for _, key := range []string{"weight", "label"} {
+		if v, ok := attrs[key]; ok {
+			if w, err := strconv.ParseFloat(v, 32); err == nil {
+				return float32(w)
+			}
+		}
+	}
+	return 1
+}
+
+// merge copies attributes from src to dst
+func merge(dst, src Attrs) {
+	for k, v := range src {
+		dst[k] = v
+	}
+}
+
+// merged returns a new attribute list with attributes of a overridden by b
+func merged(a, b Attrs) Attrs {
+	res := make(Attrs, len(a)+len(b))
+	merge(res, a)
+	merge(res, b)
+	return res
+}
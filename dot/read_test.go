@@ -0,0 +1,157 @@
+package dot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/iimos/gorka"
+	"github.com/iimos/gorka/types"
+)
+
+func TestParse(t *testing.T) {
+	type e struct {
+		a, b string
+		w    float32
+	}
+	type testcase struct {
+		text      string
+		nodeCount int
+		edgeCount int
+		edges     []e
+	}
+
+	cases := []testcase{
+		testcase{"digraph {}", 0, 0, []e{}},
+		testcase{"graph g { a }", 1, 0, []e{}},
+		testcase{"digraph { a -> b }", 2, 1, []e{{"a", "b", 1}}},
+		testcase{"graph { a -- b }", 2, 2, []e{{"a", "b", 1}, {"b", "a", 1}}},
+		testcase{"digraph { a -> b -> c; }", 3, 2, []e{{"a", "b", 1}, {"b", "c", 1}}},
+		testcase{"digraph { a -> {b c} }", 3, 2, []e{{"a", "b", 1}, {"a", "c", 1}}},
+		testcase{"digraph { subgraph s { a b } -> subgraph { c } }", 3, 2, []e{{"a", "c", 1}, {"b", "c", 1}}},
+		testcase{"digraph { a -> b [weight=2.5] }", 2, 1, []e{{"a", "b", 2.5}}},
+		testcase{"digraph { a -> b [label=\"3\"] }", 2, 1, []e{{"a", "b", 3}}},
+		testcase{"digraph { a -> b [label=x] }", 2, 1, []e{{"a", "b", 1}}},
+		testcase{"digraph { edge [weight=4] a -> b; c -> d [weight=5] }", 4, 2, []e{{"a", "b", 4}, {"c", "d", 5}}},
+		testcase{"digraph { { edge [weight=4] a -> b } c -> d }", 4, 2, []e{{"a", "b", 4}, {"c", "d", 1}}},
+		testcase{"strict digraph { a -> b; a -> b }", 2, 1, []e{{"a", "b", 1}}},
+		testcase{"DiGraph { Node [shape=box]; a:p1:n -> b:sw }", 2, 1, []e{{"a", "b", 1}}},
+		testcase{"digraph { \"a b\" -> \"c\\\"d\" -> -1.5 }", 3, 2, []e{{"a b", "c\"d", 1}, {"c\"d", "-1.5", 1}}},
+		testcase{"digraph { \"a\" + \"b\" -> <x<i>y</i>> }", 2, 1, []e{{"ab", "x<i>y</i>", 1}}},
+		testcase{"digraph { \"node\" -> \"edge\" }", 2, 1, []e{{"node", "edge", 1}}},
+		testcase{`
+			# generated
+			digraph G {
+				// comment
+				rankdir = LR; /* multi
+				line */
+				a -> b [color=red, style=bold; weight=2][arrowhead=none]
+				йö -> 漢字
+			}
+		`, 4, 2, []e{{"a", "b", 2}, {"йö", "漢字", 1}}},
+	}
+
+	for i, c := range cases {
+		g := gorka.New()
+		if err := Parse(g, c.text); err != nil {
+			t.Errorf("#%d: parse error: %s", i, err)
+			continue
+		}
+		if g.NodesCount() != c.nodeCount {
+			t.Errorf("#%d: wrong nodes count - %d, expected %d", i, g.NodesCount(), c.nodeCount)
+		}
+		if g.EdgesCount() != c.edgeCount {
+			t.Errorf("#%d: wrong edges count - %d, expected %d", i, g.EdgesCount(), c.edgeCount)
+		}
+		for _, e := range c.edges {
+			a, ok := g.NodeByLabel(e.a)
+			if !ok {
+				t.Errorf("#%d: no node with label %s", i, e.a)
+				continue
+			}
+			found := false
+			g.NodeEdgeIter(a, func(edge types.Edge) bool {
+				if edge.Dst().Label() == e.b {
+					found = true
+					if edge.Wieght() != e.w {
+						t.Errorf("#%d: wrong weight of %s->%s: %f", i, e.a, e.b, edge.Wieght())
+					}
+				}
+				return true
+			})
+			if !found {
+				t.Errorf("#%d: no edge %s->%s", i, e.a, e.b)
+			}
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []string{
+		"",
+		"a -> b",
+		"digraph {",
+		"digraph { a -- b }",
+		"graph { a -> b }",
+		"digraph { a -> }",
+		"digraph { a [color] }",
+		"digraph { a [color=] }",
+		"digraph { \"a }",
+		"digraph { a } b",
+		"digraph { a -> <b }",
+		"digraph { a ! b }",
+	}
+	for i, text := range cases {
+		if err := Parse(gorka.New(), text); err == nil {
+			t.Errorf("#%d: '%s' parsed without error", i, text)
+		}
+	}
+
+	if err := Parse(nil, "digraph {}"); err == nil {
+		t.Errorf("parse should not accept nil graphs")
+	}
+}
+
+func TestDecoderAttrs(t *testing.T) {
+	g := gorka.New()
+	d := NewDecoder(strings.NewReader(`strict digraph G {
+		label = "top"
+		graph [rankdir=LR]
+		node [shape=box]
+		a [color=red]
+		a -> b [weight=2]
+		subgraph { graph [label=inner] node [shape=circle] c }
+	}`))
+
+	nodes := map[string]Attrs{}
+	d.OnNode(func(n types.Node, attrs Attrs) {
+		nodes[n.Label()] = attrs
+	})
+	edges := 0
+	d.OnEdge(func(e types.Edge, attrs Attrs) {
+		edges++
+		if attrs["weight"] != "2" {
+			t.Errorf("wrong edge attrs: %v", attrs)
+		}
+	})
+
+	if err := d.Decode(g); err != nil {
+		t.Fatalf("decode error: %s", err)
+	}
+
+	h := d.Header()
+	if !h.Strict || !h.Directed || h.Name != "G" || h.Attrs["label"] != "top" || h.Attrs["rankdir"] != "LR" {
+		t.Errorf("wrong header: %+v", h)
+	}
+	if edges != 1 {
+		t.Errorf("wrong edges count: %d", edges)
+	}
+	if nodes["a"]["shape"] != "box" || nodes["a"]["color"] != "red" {
+		t.Errorf("wrong attrs of a: %v", nodes["a"])
+	}
+	if nodes["b"]["shape"] != "box" {
+		t.Errorf("wrong attrs of b: %v", nodes["b"])
+	}
+	if nodes["c"]["shape"] != "circle" {
+		t.Errorf("wrong attrs of c: %v", nodes["c"])
+	}
+}
@@ -0,0 +1,220 @@
+package dot
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iimos/gorka/internal/graphutil"
+	"github.com/iimos/gorka/types"
+)
+
+// Attrs is a DOT attribute list
+type Attrs map[string]string
+
+// Options controls how a graph is written
+type Options struct {
+	// Name is the graph ID, empty means anonymous graph
+	Name string
+	// Undirected writes `graph` with `--` edges, see graphutil.SkipOpposite
+	Undirected bool
+	// Strict writes `strict` graph
+	Strict bool
+	// Weights writes edge weights as edge labels
+	Weights bool
+
+	// GraphAttrs are written as graph attributes
+	GraphAttrs Attrs
+	// NodeAttrs returns attributes of the node, may be nil
+	NodeAttrs func(n types.Node) Attrs
+	// EdgeAttrs returns attributes of the edge, may be nil
+	EdgeAttrs func(e types.Edge) Attrs
+}
+
+// Write writes the graph in DOT language. Nodes are identified by labels,
+// unlabeled nodes by their IDs, so it fails when the ID of an unlabeled node
+// equals the label of another node. Nil opt means a directed graph without attributes.
+func Write(w io.Writer, g types.Graph, opt *Options) error {
+	if opt == nil {
+		opt = &Options{}
+	}
+	if err := graphutil.CheckNames(g); err != nil {
+		return err
+	}
+	b := bufio.NewWriter(w)
+
+	if opt.Strict {
+		b.WriteString("strict ")
+	}
+	edgeop := " -> "
+	if opt.Undirected {
+		b.WriteString("graph ")
+		edgeop = " -- "
+	} else {
+		b.WriteString("digraph ")
+	}
+	if opt.Name != "" {
+		b.WriteString(quote(opt.Name))
+		b.WriteByte(' ')
+	}
+	b.WriteString("{\n")
+
+	if len(opt.GraphAttrs) > 0 {
+		b.WriteString("\tgraph")
+		writeAttrs(b, opt.GraphAttrs)
+		b.WriteString(";\n")
+	}
+
+	g.NodeIter(func(n types.Node) bool {
+		b.WriteByte('\t')
+		b.WriteString(nodeID(n))
+		if opt.NodeAttrs != nil {
+			writeAttrs(b, opt.NodeAttrs(n))
+		}
+		b.WriteString(";\n")
+		return true
+	})
+
+	g.NodeIter(func(n types.Node) bool {
+		for _, e := range graphutil.SortedEdges(g, n) {
+			d := e.Dst()
+			if opt.Undirected && graphutil.SkipOpposite(g, n, d) {
+				continue
+			}
+
+			b.WriteByte('\t')
+			b.WriteString(nodeID(n))
+			b.WriteString(edgeop)
+			b.WriteString(nodeID(d))
+
+			var attrs Attrs
+			if opt.EdgeAttrs != nil {
+				attrs = opt.EdgeAttrs(e)
+			}
+			if opt.Weights {
+				if _, ok := attrs["label"]; !ok {
+					withLabel := Attrs{"label": strconv.FormatFloat(float64(e.Wieght()), 'g', -1, 32)}
+					for k, v := range attrs {
+						withLabel[k] = v
+					}
+					attrs = withLabel
+				}
+			}
+			writeAttrs(b, attrs)
+			b.WriteString(";\n")
+		}
+		return true
+	})
+
+	b.WriteString("}\n")
+	return b.Flush()
+}
+
+// String returns the graph in DOT language, or an empty string if it can't be written
+func String(g types.Graph, opt *Options) string {
+	var s strings.Builder
+	Write(&s, g, opt)
+	return s.String()
+}
+
+func writeAttrs(b *bufio.Writer, attrs Attrs) {
+	if len(attrs) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	b.WriteString(" [")
+	for i, k := range keys {
+		if i != 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(quote(k))
+		b.WriteByte('=')
+		b.WriteString(quoteValue(attrs[k]))
+	}
+	b.WriteByte(']')
+}
+
+func nodeID(n types.Node) string {
+	if l := n.Label(); l != "" {
+		return quote(l)
+	}
+	return strconv.Itoa(n.ID())
+}
+
+// quote returns s as is if it is a valid DOT identifier or numeral and a quoted string otherwise
+func quote(s string) string {
+	return quoteEscaping(s, `"\`)
+}
+
+// quoteValue quotes attribute value keeping its backslashes,
+// so escapes like \n and \l in labels work
+func quoteValue(s string) string {
+	return quoteEscaping(s, `"`)
+}
+
+func quoteEscaping(s, special string) string {
+	if isID(s) && !isKeyword(s) || isNumeral(s) {
+		return s
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		if strings.ContainsRune(special, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func isID(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if !isIDRune(r) || i == 0 && r >= '0' && r <= '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isIDRune(r rune) bool {
+	return r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r >= 0x80
+}
+
+func isNumeral(s string) bool {
+	if strings.HasPrefix(s, "-") {
+		s = s[1:]
+	}
+	if s == "" || s == "." {
+		return false
+	}
+	dot := false
+	for _, r := range s {
+		switch {
+		case r == '.' && !dot:
+			dot = true
+		case r >= '0' && r <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func isKeyword(s string) bool {
+	switch strings.ToLower(s) {
+	case "node", "edge", "graph", "digraph", "subgraph", "strict":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package dot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/iimos/gorka"
+	"github.com/iimos/gorka/gralang"
+	"github.com/iimos/gorka/types"
+)
+
+func TestWrite(t *testing.T) {
+	type tcase struct {
+		s   string
+		opt *Options
+		dot string
+	}
+	cases := []tcase{
+		tcase{"", nil, "digraph {\n}\n"},
+		tcase{"a -> b c", nil, "digraph {\n\ta;\n\tb;\n\tc;\n\ta -> b;\n\ta -> c;\n}\n"},
+		tcase{"a -- b; b -> c", &Options{Undirected: true, Name: "g"},
+			"graph g {\n\ta;\n\tb;\n\tc;\n\ta -- b;\n\tb -- c;\n}\n"},
+		tcase{"a -> b", &Options{Strict: true, Weights: true},
+			"strict digraph {\n\ta;\n\tb;\n\ta -> b [label=1];\n}\n"},
+		tcase{"node -> \"x y\"", nil, "digraph {\n\t\"node\";\n\t\"\\\"x\";\n\t\"y\\\"\";\n\t\"node\" -> \"\\\"x\";\n\t\"node\" -> \"y\\\"\";\n}\n"},
+		tcase{"a -> 1.5", &Options{GraphAttrs: Attrs{"rankdir": "LR", "label": "my graph"}},
+			"digraph {\n\tgraph [label=\"my graph\", rankdir=LR];\n\ta;\n\t1.5;\n\ta -> 1.5;\n}\n"},
+	}
+
+	for i, c := range cases {
+		g := gorka.New()
+		if err := gralang.Parse(g, c.s); err != nil {
+			t.Errorf("#%d: parse error: %s", i, err)
+			continue
+		}
+		res := String(g, c.opt)
+		if res != c.dot {
+			t.Errorf("#%d: wrong output:\n%s\nexpected:\n%s", i, res, c.dot)
+		}
+	}
+}
+
+func TestWriteAttrs(t *testing.T) {
+	g := gorka.New()
+	a, _ := g.NewNode("a")
+	b, _ := g.NewNode("")
+	g.AddEdge(a, b, 2.5)
+
+	opt := &Options{
+		Weights: true,
+		NodeAttrs: func(n types.Node) Attrs {
+			if n.Label() == "" {
+				return nil
+			}
+			return Attrs{"shape": "box"}
+		},
+		EdgeAttrs: func(e types.Edge) Attrs {
+			return Attrs{"color": "red"}
+		},
+	}
+	expected := "digraph {\n\ta [shape=box];\n\t2;\n\ta -> 2 [color=red, label=2.5];\n}\n"
+	if res := String(g, opt); res != expected {
+		t.Errorf("wrong output:\n%s\nexpected:\n%s", res, expected)
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	src := gorka.New()
+	gralang.Parse(src, "a -> b c; b -- c; c -> \"d e\"")
+
+	for _, undirected := range []bool{false, true} {
+		g := gorka.New()
+		s := String(src, &Options{Undirected: undirected, Weights: true})
+		if err := Parse(g, s); err != nil {
+			t.Errorf("undirected=%v: parse error: %s\n%s", undirected, err, s)
+			continue
+		}
+		if g.NodesCount() != src.NodesCount() {
+			t.Errorf("undirected=%v: wrong nodes count %d", undirected, g.NodesCount())
+		}
+		expected := src.EdgesCount()
+		if undirected {
+			expected = 10
+		}
+		if g.EdgesCount() != expected {
+			t.Errorf("undirected=%v: wrong edges count %d, expected %d", undirected, g.EdgesCount(), expected)
+		}
+	}
+}
+
+func TestWriteReadLabels(t *testing.T) {
+	labels := []string{`a\`, `\`, `b\"c`, `"`, `d\\e`, "f\ng"}
+	src := gorka.New()
+	var prev types.Node
+	for _, l := range labels {
+		n, _ := src.NewNode(l)
+		if prev != nil {
+			src.AddEdge(prev, n, 1)
+		}
+		prev = n
+	}
+
+	var s strings.Builder
+	if err := Write(&s, src, nil); err != nil {
+		t.Fatalf("write error: %s", err)
+	}
+	g := gorka.New()
+	if err := Parse(g, s.String()); err != nil {
+		t.Fatalf("parse error: %s\n%s", err, s.String())
+	}
+	if g.NodesCount() != len(labels) || g.EdgesCount() != len(labels)-1 {
+		t.Errorf("wrong graph:\n%s\nwritten as:\n%s", g, s.String())
+	}
+	for _, l := range labels {
+		if _, ok := g.NodeByLabel(l); !ok {
+			t.Errorf("no node labeled %q in:\n%s", l, s.String())
+		}
+	}
+}
+
+func TestWriteNameCollision(t *testing.T) {
+	g := gorka.New()
+	a, _ := g.NewNode("")
+	b, _ := g.NewNode("1")
+	g.AddEdge(a, b, 1)
+
+	var s strings.Builder
+	if err := Write(&s, g, nil); err == nil {
+		t.Errorf("unlabeled node 1 and node labeled \"1\" written without error:\n%s", s.String())
+	}
+}
@@ -0,0 +1,336 @@
+package edgelist
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/iimos/gorka/internal/graphutil"
+	"github.com/iimos/gorka/types"
+)
+
+// Options describes the layout of edge and adjacency list files.
+// Nil options mean whitespace separated directed unweighted lists with
+// '#' and '%' comments, which is what SNAP and KONECT datasets use.
+type Options struct {
+	// Comma is the field delimiter, zero means any run of spaces and tabs.
+	// With a delimiter fields are read as CSV, so they may be quoted.
+	Comma rune
+	// Comment lists characters that start a comment line, default is "#%"
+	Comment string
+	// Header skips the first non-comment line
+	Header bool
+	// Weighted reads and writes the third column of edge lists as edge weight
+	Weighted bool
+	// Undirected adds every edge in both directions on read,
+	// on write see graphutil.SkipOpposite
+	Undirected bool
+	// Labels maps IDs used in the file to node labels, IDs absent
+	// in the map are used as labels as is
+	Labels map[string]string
+}
+
+func (opt *Options) comment() string {
+	if opt.Comment == "" {
+		return "#%"
+	}
+	return opt.Comment
+}
+
+// records reads non-comment lines split into fields
+type records struct {
+	opt    *Options
+	scan   *bufio.Scanner
+	fields []string
+	line   int
+
+	headerSkipped bool
+}
+
+const maxLineSize = 64 * 1024 * 1024
+
+func newRecords(r io.Reader, opt *Options) (*records, error) {
+	r, err := maybeGzip(r)
+	if err != nil {
+		return nil, err
+	}
+	rs := &records{opt: opt, scan: bufio.NewScanner(r)}
+	rs.scan.Buffer(make([]byte, 64*1024), maxLineSize)
+	return rs, nil
+}
+
+// next returns fields of the next record or io.EOF
+func (rs *records) next() ([]string, error) {
+	for {
+		if !rs.scan.Scan() {
+			if err := rs.scan.Err(); err != nil {
+				return nil, err
+			}
+			return nil, io.EOF
+		}
+		rs.line++
+
+		line := strings.TrimSpace(rs.scan.Text())
+		if line == "" {
+			continue
+		}
+		if first, _ := utf8.DecodeRuneInString(line); strings.ContainsRune(rs.opt.comment(), first) {
+			continue
+		}
+
+		var fields []string
+		if rs.opt.Comma == 0 {
+			fields = strings.Fields(line)
+		} else {
+			var err error
+			if rs.fields, err = splitQuoted(rs.fields[:0], line, rs.opt.Comma); err != nil {
+				return nil, rs.errorf("%s", err)
+			}
+			fields = rs.fields
+		}
+
+		for len(fields) > 0 && fields[len(fields)-1] == "" {
+			// trailing delimiters
+			fields = fields[:len(fields)-1]
+		}
+		if rs.opt.Header && !rs.headerSkipped {
+			rs.headerSkipped = true
+			continue
+		}
+		for _, f := range fields {
+			if f == "" {
+				return nil, rs.errorf("empty field")
+			}
+		}
+		return fields, nil
+	}
+}
+
+// splitQuoted splits a line by comma, fields may be enclosed in double quotes
+// with "" standing for a quote inside. Spaces around fields are trimmed.
+func splitQuoted(fields []string, line string, comma rune) ([]string, error) {
+	for {
+		line = strings.TrimLeft(line, " \t")
+		var f string
+		if strings.HasPrefix(line, `"`) {
+			var b strings.Builder
+			i := 1
+			for {
+				j := strings.IndexByte(line[i:], '"')
+				if j < 0 {
+					return nil, errors.New("unterminated quoted field")
+				}
+				b.WriteString(line[i : i+j])
+				i += j + 1
+				if !strings.HasPrefix(line[i:], `"`) {
+					break
+				}
+				b.WriteByte('"')
+				i++
+			}
+			f = b.String()
+			line = strings.TrimLeft(line[i:], " \t")
+			if line != "" {
+				if r, _ := utf8.DecodeRuneInString(line); r != comma {
+					return nil, errors.New("extra characters after quoted field")
+				}
+			}
+		} else {
+			i := strings.IndexRune(line, comma)
+			if i < 0 {
+				i = len(line)
+			}
+			f = strings.TrimSpace(line[:i])
+			line = line[i:]
+		}
+		fields = append(fields, f)
+		if line == "" {
+			return fields, nil
+		}
+		_, sz := utf8.DecodeRuneInString(line)
+		line = line[sz:]
+		if line == "" {
+			// trailing comma
+			return append(fields, ""), nil
+		}
+	}
+}
+
+func (rs *records) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", rs.line, fmt.Sprintf(format, args...))
+}
+
+// maybeGzip returns a reader of decompressed data if r is gzipped
+func maybeGzip(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		return gzip.NewReader(br)
+	}
+	return br, nil
+}
+
+// nodes resolves file IDs to graph nodes
+type nodes struct {
+	*graphutil.Nodes
+	g   types.Graph
+	opt *Options
+}
+
+func newNodes(g types.Graph, opt *Options) *nodes {
+	return &nodes{Nodes: graphutil.NewNodes(g), g: g, opt: opt}
+}
+
+func (ns *nodes) get(id string) types.Node {
+	n, ok := ns.Get(id)
+	if !ok {
+		n, _ = ns.Add(id, ns.opt.Labels[id])
+	}
+	return n
+}
+
+func (ns *nodes) connect(a, b types.Node, w float32) {
+	if ns.opt.Undirected {
+		ns.g.AddBiEdge(a, b, w)
+	} else {
+		ns.g.AddEdge(a, b, w)
+	}
+}
+
+// ReadEdges reads an edge list: `src dst [weight]` per line.
+// Lines with a single ID add isolated nodes. Gzipped input is detected automatically.
+func ReadEdges(g types.Graph, r io.Reader, opt *Options) error {
+	if g == nil {
+		return errors.New("graph is empty")
+	}
+	if opt == nil {
+		opt = &Options{}
+	}
+	rs, err := newRecords(r, opt)
+	if err != nil {
+		return err
+	}
+	ns := newNodes(g, opt)
+
+	for {
+		fields, err := rs.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if len(fields) == 1 {
+			ns.get(fields[0])
+			continue
+		}
+
+		w := float32(1)
+		if opt.Weighted {
+			if len(fields) < 3 {
+				return rs.errorf("weight is missing")
+			}
+			f, err := strconv.ParseFloat(fields[2], 32)
+			if err != nil {
+				return rs.errorf("wrong weight '%s'", fields[2])
+			}
+			w = float32(f)
+		}
+		ns.connect(ns.get(fields[0]), ns.get(fields[1]), w)
+	}
+}
+
+// ReadAdjacency reads an adjacency list: `node neighbour1 neighbour2 ...` per line.
+// Edges get weight 1. Gzipped input is detected automatically.
+func ReadAdjacency(g types.Graph, r io.Reader, opt *Options) error {
+	if g == nil {
+		return errors.New("graph is empty")
+	}
+	if opt == nil {
+		opt = &Options{}
+	}
+	rs, err := newRecords(r, opt)
+	if err != nil {
+		return err
+	}
+	ns := newNodes(g, opt)
+
+	for {
+		fields, err := rs.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		src := ns.get(fields[0])
+		for _, id := range fields[1:] {
+			ns.connect(src, ns.get(id), 1)
+		}
+	}
+}
+
+// ReadLabels reads `id label` lines into a map suitable for Options.Labels.
+// Labels may contain delimiters, everything after the first field is the label.
+func ReadLabels(r io.Reader, opt *Options) (map[string]string, error) {
+	if opt == nil {
+		opt = &Options{}
+	}
+	rs, err := newRecords(r, opt)
+	if err != nil {
+		return nil, err
+	}
+	sep := " "
+	if opt.Comma != 0 {
+		sep = string(opt.Comma)
+	}
+
+	labels := make(map[string]string)
+	for {
+		fields, err := rs.next()
+		if err == io.EOF {
+			return labels, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) < 2 {
+			return nil, rs.errorf("label is missing")
+		}
+		labels[fields[0]] = strings.Join(fields[1:], sep)
+	}
+}
+
+// ReadEdgesFile reads an edge list from the named file, which may be gzipped
+func ReadEdgesFile(g types.Graph, name string, opt *Options) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := ReadEdges(g, f, opt); err != nil {
+		return fmt.Errorf("%s: %s", name, err)
+	}
+	return nil
+}
+
+// ReadAdjacencyFile reads an adjacency list from the named file, which may be gzipped
+func ReadAdjacencyFile(g types.Graph, name string, opt *Options) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := ReadAdjacency(g, f, opt); err != nil {
+		return fmt.Errorf("%s: %s", name, err)
+	}
+	return nil
+}
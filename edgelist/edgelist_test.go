@@ -0,0 +1,251 @@
+package edgelist
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iimos/gorka"
+	"github.com/iimos/gorka/gralang"
+	"github.com/iimos/gorka/internal/graphtest"
+)
+
+func TestReadEdges(t *testing.T) {
+	type e struct {
+		a, b string
+		w    float32
+	}
+	type tcase struct {
+		text      string
+		opt       *Options
+		nodeCount int
+		edgeCount int
+		edges     []e
+	}
+	cases := []tcase{
+		tcase{"", nil, 0, 0, nil},
+		tcase{"# Directed graph\n# FromNodeId\tToNodeId\n0\t1\n0\t2\n1 2\n", nil, 3, 3,
+			[]e{{"0", "1", 1}, {"0", "2", 1}, {"1", "2", 1}}},
+		tcase{"% sym unweighted\n1 2\n\n2 3\n4\n", &Options{Undirected: true}, 4, 4,
+			[]e{{"1", "2", 1}, {"2", "1", 1}, {"3", "2", 1}}},
+		tcase{"a b 2.5\nb c -1\n", &Options{Weighted: true}, 3, 2,
+			[]e{{"a", "b", 2.5}, {"b", "c", -1}}},
+		tcase{"src,dst,w\na, b ,3\n\"c,d\",a,4\n", &Options{Comma: ',', Header: true, Weighted: true}, 3, 2,
+			[]e{{"a", "b", 3}, {"c,d", "a", 4}}},
+		tcase{"; comment\na;b;\n", &Options{Comma: ';', Comment: ";"}, 2, 1,
+			[]e{{"a", "b", 1}}},
+		tcase{"1 2\n2 3\n", &Options{Labels: map[string]string{"1": "one", "2": "two"}}, 3, 2,
+			[]e{{"one", "two", 1}, {"two", "3", 1}}},
+	}
+
+	for i, c := range cases {
+		g := gorka.New()
+		if err := ReadEdges(g, strings.NewReader(c.text), c.opt); err != nil {
+			t.Errorf("#%d: read error: %s", i, err)
+			continue
+		}
+		if g.NodesCount() != c.nodeCount {
+			t.Errorf("#%d: wrong nodes count - %d, expected %d", i, g.NodesCount(), c.nodeCount)
+		}
+		if g.EdgesCount() != c.edgeCount {
+			t.Errorf("#%d: wrong edges count - %d, expected %d", i, g.EdgesCount(), c.edgeCount)
+		}
+		for _, e := range c.edges {
+			w, ok := graphtest.Weight(g, e.a, e.b)
+			if !ok || w != e.w {
+				t.Errorf("#%d: no edge %s->%s of weight %f", i, e.a, e.b, e.w)
+			}
+		}
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	type tcase struct {
+		text string
+		opt  *Options
+	}
+	cases := []tcase{
+		tcase{"a b\nc d", &Options{Weighted: true}},
+		tcase{"a b x", &Options{Weighted: true}},
+		tcase{"a,,b", &Options{Comma: ','}},
+		tcase{"a,\"b", &Options{Comma: ','}},
+	}
+	for i, c := range cases {
+		err := ReadEdges(gorka.New(), strings.NewReader(c.text), c.opt)
+		if err == nil {
+			t.Errorf("#%d: '%s' read without error", i, c.text)
+		}
+	}
+	if err := ReadEdges(nil, strings.NewReader(""), nil); err == nil {
+		t.Errorf("nil graph should fail")
+	}
+	if err := ReadEdges(gorka.New(), strings.NewReader("a\nb c 1\nd e"), &Options{Weighted: true}); err == nil || !strings.HasPrefix(err.Error(), "line 3:") {
+		t.Errorf("error should point to line 3: %v", err)
+	}
+}
+
+func TestReadAdjacency(t *testing.T) {
+	g := gorka.New()
+	text := "# adjacency\n1 2 3 4\n2 3\n5\n"
+	if err := ReadAdjacency(g, strings.NewReader(text), nil); err != nil {
+		t.Fatalf("read error: %s", err)
+	}
+	if g.NodesCount() != 5 || g.EdgesCount() != 4 {
+		t.Errorf("wrong graph:\n%s", g)
+	}
+
+	g = gorka.New()
+	if err := ReadAdjacency(g, strings.NewReader("a,b,c\n"), &Options{Comma: ',', Undirected: true}); err != nil {
+		t.Fatalf("read error: %s", err)
+	}
+	if g.NodesCount() != 3 || g.EdgesCount() != 4 {
+		t.Errorf("wrong undirected graph:\n%s", g)
+	}
+}
+
+func TestReadLabels(t *testing.T) {
+	labels, err := ReadLabels(strings.NewReader("# id name\n1 New York\n2 Paris\n"), nil)
+	if err != nil {
+		t.Fatalf("read error: %s", err)
+	}
+	if len(labels) != 2 || labels["1"] != "New York" || labels["2"] != "Paris" {
+		t.Errorf("wrong labels: %v", labels)
+	}
+
+	g := gorka.New()
+	if err := ReadEdges(g, strings.NewReader("1 2\n"), &Options{Labels: labels}); err != nil {
+		t.Fatalf("read error: %s", err)
+	}
+	if _, ok := graphtest.Weight(g, "New York", "Paris"); !ok {
+		t.Errorf("labels are not applied:\n%s", g)
+	}
+
+	if _, err := ReadLabels(strings.NewReader("1\n"), nil); err == nil {
+		t.Errorf("missing label should fail")
+	}
+}
+
+func TestReadGzip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte("# gzipped\n1 2\n2 3\n"))
+	zw.Close()
+
+	name := filepath.Join(t.TempDir(), "graph.txt.gz")
+	if err := os.WriteFile(name, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	g := gorka.New()
+	if err := ReadEdgesFile(g, name, nil); err != nil {
+		t.Fatalf("read error: %s", err)
+	}
+	if g.NodesCount() != 3 || g.EdgesCount() != 2 {
+		t.Errorf("wrong graph:\n%s", g)
+	}
+
+	g = gorka.New()
+	if err := ReadAdjacencyFile(g, name, nil); err != nil {
+		t.Fatalf("read error: %s", err)
+	}
+	if g.NodesCount() != 3 || g.EdgesCount() != 2 {
+		t.Errorf("wrong graph:\n%s", g)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	g := gorka.New()
+	gralang.Parse(g, "a -> b c; b -- c; d")
+	a, _ := g.NodeByLabel("a")
+	b, _ := g.NodeByLabel("b")
+	g.AddEdge(a, b, 2.5)
+
+	type tcase struct {
+		opt   *Options
+		adj   bool
+		lines string
+	}
+	cases := []tcase{
+		tcase{nil, false, "a\tb\na\tc\nb\tc\nc\tb\nd\n"},
+		tcase{&Options{Weighted: true, Undirected: true}, false, "a\tb\t2.5\na\tc\t1\nb\tc\t1\nd\n"},
+		tcase{&Options{Comma: ','}, false, "a,b\na,c\nb,c\nc,b\nd\n"},
+		tcase{nil, true, "a\tb\tc\nb\tc\nc\tb\nd\n"},
+		tcase{&Options{Undirected: true, Comma: ' '}, true, "a b c\nb c\nc\nd\n"},
+	}
+
+	for i, c := range cases {
+		var s strings.Builder
+		var err error
+		if c.adj {
+			err = WriteAdjacency(&s, g, c.opt)
+		} else {
+			err = WriteEdges(&s, g, c.opt)
+		}
+		if err != nil {
+			t.Errorf("#%d: write error: %s", i, err)
+			continue
+		}
+		if s.String() != c.lines {
+			t.Errorf("#%d: wrong output:\n%s\nexpected:\n%s", i, s.String(), c.lines)
+		}
+
+		g2 := gorka.New()
+		if c.adj {
+			err = ReadAdjacency(g2, strings.NewReader(s.String()), c.opt)
+		} else {
+			err = ReadEdges(g2, strings.NewReader(s.String()), c.opt)
+		}
+		if err != nil {
+			t.Errorf("#%d: read error: %s", i, err)
+			continue
+		}
+		if c.opt != nil && c.opt.Undirected {
+			// undirected lists make all edges bidirectional
+			continue
+		}
+		if g2.NodesCount() != g.NodesCount() || g2.EdgesCount() != g.EdgesCount() {
+			t.Errorf("#%d: graph changed after round trip:\n%s", i, g2)
+		}
+	}
+}
+
+func TestWriteNames(t *testing.T) {
+	g := gorka.New()
+	a, _ := g.NewNode("")
+	b, _ := g.NewNode("1")
+	g.AddEdge(a, b, 1)
+	if err := WriteEdges(&strings.Builder{}, g, nil); err == nil {
+		t.Errorf("unlabeled node 1 and node labeled \"1\" written without error")
+	}
+	if err := WriteAdjacency(&strings.Builder{}, g, &Options{Comma: ','}); err == nil {
+		t.Errorf("unlabeled node 1 and node labeled \"1\" written without error")
+	}
+
+	g = gorka.New()
+	gralang.Parse(g, "b")
+	ny, _ := g.NewNode("New York")
+	b, _ = g.NodeByLabel("b")
+	g.AddEdge(ny, b, 1)
+	if err := WriteEdges(&strings.Builder{}, g, nil); err == nil {
+		t.Errorf("label with a space written without Comma")
+	}
+	if err := WriteAdjacency(&strings.Builder{}, g, nil); err == nil {
+		t.Errorf("label with a space written without Comma")
+	}
+
+	opt := &Options{Comma: ','}
+	var s strings.Builder
+	if err := WriteEdges(&s, g, opt); err != nil {
+		t.Fatalf("write error: %s", err)
+	}
+	g2 := gorka.New()
+	if err := ReadEdges(g2, strings.NewReader(s.String()), opt); err != nil {
+		t.Fatalf("read error: %s", err)
+	}
+	if _, ok := graphtest.Weight(g2, "New York", "b"); !ok || g2.NodesCount() != 2 || g2.EdgesCount() != 1 {
+		t.Errorf("graph changed after round trip:\n%s", g2)
+	}
+}
@@ -0,0 +1,146 @@
+package edgelist
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/iimos/gorka/internal/graphutil"
+	"github.com/iimos/gorka/types"
+)
+
+// writer writes records separated with Options.Comma or tabs
+type writer struct {
+	b   *bufio.Writer
+	csv *csv.Writer
+	rec []string
+}
+
+func newWriter(w io.Writer, opt *Options) *writer {
+	wr := &writer{b: bufio.NewWriter(w)}
+	if opt.Comma != 0 {
+		wr.csv = csv.NewWriter(wr.b)
+		wr.csv.Comma = opt.Comma
+	}
+	return wr
+}
+
+func (wr *writer) write(rec []string) error {
+	if wr.csv != nil {
+		return wr.csv.Write(rec)
+	}
+	for i, f := range rec {
+		if i != 0 {
+			wr.b.WriteByte('\t')
+		}
+		wr.b.WriteString(f)
+	}
+	return wr.b.WriteByte('\n')
+}
+
+// checkNodes returns an error if some node can't be read back as written:
+// its name is taken by another node or it is split into several fields
+func checkNodes(g types.Graph, opt *Options) error {
+	if err := graphutil.CheckNames(g); err != nil {
+		return err
+	}
+	var err error
+	g.NodeIter(func(n types.Node) bool {
+		l := n.Label()
+		if opt.Comma == 0 && strings.IndexFunc(l, unicode.IsSpace) >= 0 {
+			err = fmt.Errorf("label %q has spaces, set Options.Comma to write it", l)
+		} else if strings.ContainsAny(l, "\r\n") {
+			err = fmt.Errorf("label %q has line breaks", l)
+		}
+		return err == nil
+	})
+	return err
+}
+
+func (wr *writer) flush() error {
+	if wr.csv != nil {
+		wr.csv.Flush()
+		if err := wr.csv.Error(); err != nil {
+			return err
+		}
+	}
+	return wr.b.Flush()
+}
+
+// WriteEdges writes the graph as an edge list: `src dst [weight]` per line,
+// isolated nodes are written alone. Nodes are written as labels, unlabeled
+// nodes as their IDs. Without Options.Comma fields are separated with tabs.
+// It fails when nodes can't be read back: the ID of an unlabeled node equals
+// the label of another node, or a label has spaces and Options.Comma is not set.
+func WriteEdges(w io.Writer, g types.Graph, opt *Options) error {
+	if opt == nil {
+		opt = &Options{}
+	}
+	if err := checkNodes(g, opt); err != nil {
+		return err
+	}
+	wr := newWriter(w, opt)
+
+	var err error
+	g.NodeIter(func(n types.Node) bool {
+		if g.OutDegree(n) == 0 && g.InDegree(n) == 0 {
+			err = wr.write([]string{graphutil.LabelOrID(n)})
+			return err == nil
+		}
+		for _, e := range graphutil.SortedEdges(g, n) {
+			d := e.Dst()
+			if opt.Undirected && graphutil.SkipOpposite(g, n, d) {
+				continue
+			}
+			rec := append(wr.rec[:0], graphutil.LabelOrID(n), graphutil.LabelOrID(d))
+			if opt.Weighted {
+				rec = append(rec, strconv.FormatFloat(float64(e.Wieght()), 'g', -1, 32))
+			}
+			wr.rec = rec
+			if err = wr.write(rec); err != nil {
+				return false
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	return wr.flush()
+}
+
+// WriteAdjacency writes the graph as an adjacency list: a node followed by
+// its neighbours per line. Weights are not written. Nodes are written and
+// checked as in WriteEdges.
+func WriteAdjacency(w io.Writer, g types.Graph, opt *Options) error {
+	if opt == nil {
+		opt = &Options{}
+	}
+	if err := checkNodes(g, opt); err != nil {
+		return err
+	}
+	wr := newWriter(w, opt)
+
+	var err error
+	g.NodeIter(func(n types.Node) bool {
+		rec := append(wr.rec[:0], graphutil.LabelOrID(n))
+		for _, e := range graphutil.SortedEdges(g, n) {
+			d := e.Dst()
+			if opt.Undirected && graphutil.SkipOpposite(g, n, d) {
+				continue
+			}
+			rec = append(rec, graphutil.LabelOrID(d))
+		}
+		wr.rec = rec
+		err = wr.write(rec)
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	return wr.flush()
+}
@@ -0,0 +1,175 @@
+package queue
+
+// minCapacity is the size of the ring buffer allocated on the first push
+const minCapacity = 16
+
+// Deque is a double-ended queue backed by a growable ring buffer.
+// Pushes and pops at both ends take amortized O(1) time and reuse
+// the buffer, which shrinks when the deque gets mostly empty.
+// Deque is not safe for concurrent use, see SyncDeque. The zero value
+// is an empty deque ready to use.
+type Deque[T any] struct {
+	buf  []T // len(buf) is zero or a power of two
+	head int // index of the first item in buf
+	n    int
+}
+
+// Len returns number of items in the deque
+func (d *Deque[T]) Len() int {
+	return d.n
+}
+
+// PushBack adds the item to the back of the deque
+func (d *Deque[T]) PushBack(v T) {
+	if d.n == len(d.buf) {
+		d.resize(2 * len(d.buf))
+	}
+	d.buf[(d.head+d.n)&(len(d.buf)-1)] = v
+	d.n++
+}
+
+// PushFront adds the item to the front of the deque
+func (d *Deque[T]) PushFront(v T) {
+	if d.n == len(d.buf) {
+		d.resize(2 * len(d.buf))
+	}
+	d.head = (d.head - 1) & (len(d.buf) - 1)
+	d.buf[d.head] = v
+	d.n++
+}
+
+// PopFront removes and returns the front item, false if the deque is empty
+func (d *Deque[T]) PopFront() (T, bool) {
+	var zero T
+	if d.n == 0 {
+		return zero, false
+	}
+	v := d.buf[d.head]
+	d.buf[d.head] = zero // don't hold the item for GC
+	d.head = (d.head + 1) & (len(d.buf) - 1)
+	d.n--
+	d.shrink()
+	return v, true
+}
+
+// PopBack removes and returns the back item, false if the deque is empty
+func (d *Deque[T]) PopBack() (T, bool) {
+	var zero T
+	if d.n == 0 {
+		return zero, false
+	}
+	i := (d.head + d.n - 1) & (len(d.buf) - 1)
+	v := d.buf[i]
+	d.buf[i] = zero
+	d.n--
+	d.shrink()
+	return v, true
+}
+
+// Front returns the front item without removing it, false if the deque is empty
+func (d *Deque[T]) Front() (T, bool) {
+	if d.n == 0 {
+		var zero T
+		return zero, false
+	}
+	return d.buf[d.head], true
+}
+
+// Back returns the back item without removing it, false if the deque is empty
+func (d *Deque[T]) Back() (T, bool) {
+	if d.n == 0 {
+		var zero T
+		return zero, false
+	}
+	return d.buf[(d.head+d.n-1)&(len(d.buf)-1)], true
+}
+
+// At returns the i-th item from the front, it panics if i is out of range
+func (d *Deque[T]) At(i int) T {
+	if i < 0 || i >= d.n {
+		panic("queue: index out of range")
+	}
+	return d.buf[(d.head+i)&(len(d.buf)-1)]
+}
+
+// Clear removes all items and releases the buffer
+func (d *Deque[T]) Clear() {
+	*d = Deque[T]{}
+}
+
+// shrink halves the buffer when it is a quarter full
+func (d *Deque[T]) shrink() {
+	if len(d.buf) > minCapacity && d.n <= len(d.buf)/4 {
+		d.resize(len(d.buf) / 2)
+	}
+}
+
+// resize moves items to a new buffer of the given capacity
+func (d *Deque[T]) resize(capacity int) {
+	if capacity < minCapacity {
+		capacity = minCapacity
+	}
+	buf := make([]T, capacity)
+	if d.n > 0 {
+		if d.head+d.n <= len(d.buf) {
+			copy(buf, d.buf[d.head:d.head+d.n])
+		} else {
+			k := copy(buf, d.buf[d.head:])
+			copy(buf[k:], d.buf[:d.n-k])
+		}
+	}
+	d.buf = buf
+	d.head = 0
+}
+
+// Queue is a first-in first-out queue, not safe for concurrent use.
+// The zero value is an empty queue ready to use.
+type Queue[T any] struct {
+	d Deque[T]
+}
+
+// Len returns number of items in the queue
+func (q *Queue[T]) Len() int {
+	return q.d.Len()
+}
+
+// Push adds the item to the end of the queue
+func (q *Queue[T]) Push(v T) {
+	q.d.PushBack(v)
+}
+
+// Pop removes and returns the first item, false if the queue is empty
+func (q *Queue[T]) Pop() (T, bool) {
+	return q.d.PopFront()
+}
+
+// Peek returns the first item without removing it, false if the queue is empty
+func (q *Queue[T]) Peek() (T, bool) {
+	return q.d.Front()
+}
+
+// Stack is a last-in first-out stack, not safe for concurrent use.
+// The zero value is an empty stack ready to use.
+type Stack[T any] struct {
+	d Deque[T]
+}
+
+// Len returns number of items on the stack
+func (s *Stack[T]) Len() int {
+	return s.d.Len()
+}
+
+// Push adds the item on top of the stack
+func (s *Stack[T]) Push(v T) {
+	s.d.PushBack(v)
+}
+
+// Pop removes and returns the top item, false if the stack is empty
+func (s *Stack[T]) Pop() (T, bool) {
+	return s.d.PopBack()
+}
+
+// Peek returns the top item without removing it, false if the stack is empty
+func (s *Stack[T]) Peek() (T, bool) {
+	return s.d.Back()
+}
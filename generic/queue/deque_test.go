@@ -0,0 +1,138 @@
+package queue
+
+import (
+	"testing"
+)
+
+func TestDeque(t *testing.T) {
+	d := Deque[int]{}
+	if _, ok := d.PopFront(); ok {
+		t.Error("empty deque should pop nothing from front")
+	}
+	if _, ok := d.PopBack(); ok {
+		t.Error("empty deque should pop nothing from back")
+	}
+	if _, ok := d.Front(); ok {
+		t.Error("empty deque should have no front")
+	}
+
+	// model is a plain slice doing the same
+	model := []int{}
+	for i := 0; i < 1000; i++ {
+		switch i % 7 {
+		case 0, 1, 2:
+			d.PushBack(i)
+			model = append(model, i)
+		case 3, 4:
+			d.PushFront(i)
+			model = append([]int{i}, model...)
+		case 5:
+			v, ok := d.PopFront()
+			if ok != (len(model) > 0) || ok && v != model[0] {
+				t.Fatalf("#%d: PopFront returned %d %v", i, v, ok)
+			}
+			if ok {
+				model = model[1:]
+			}
+		case 6:
+			v, ok := d.PopBack()
+			if ok != (len(model) > 0) || ok && v != model[len(model)-1] {
+				t.Fatalf("#%d: PopBack returned %d %v", i, v, ok)
+			}
+			if ok {
+				model = model[:len(model)-1]
+			}
+		}
+		if d.Len() != len(model) {
+			t.Fatalf("#%d: wrong length %d, expected %d", i, d.Len(), len(model))
+		}
+	}
+	for i, v := range model {
+		if d.At(i) != v {
+			t.Fatalf("wrong item #%d: %d, expected %d", i, d.At(i), v)
+		}
+	}
+	if f, _ := d.Front(); f != model[0] {
+		t.Errorf("wrong front %d", f)
+	}
+	if b, _ := d.Back(); b != model[len(model)-1] {
+		t.Errorf("wrong back %d", b)
+	}
+
+	d.Clear()
+	if d.Len() != 0 || d.buf != nil {
+		t.Errorf("deque is not cleared")
+	}
+}
+
+func TestDequeShrink(t *testing.T) {
+	d := Deque[*qitem]{}
+	for i := 0; i < 10000; i++ {
+		d.PushBack(&qitem{i: i})
+	}
+	if len(d.buf) < 10000 {
+		t.Fatalf("buffer is not grown: %d", len(d.buf))
+	}
+	for i := 0; i < 9990; i++ {
+		if v, _ := d.PopFront(); v.i != i {
+			t.Fatalf("wrong item %d, expected %d", v.i, i)
+		}
+	}
+	if len(d.buf) > 64 {
+		t.Errorf("buffer is not shrunk: %d", len(d.buf))
+	}
+	for i := range d.buf {
+		if v := d.buf[i]; v != nil && v.i < 9990 {
+			t.Errorf("popped item %d is still referenced", v.i)
+		}
+	}
+}
+
+func TestQueueStack(t *testing.T) {
+	q := Queue[string]{}
+	s := Stack[string]{}
+	for _, v := range []string{"a", "b", "c"} {
+		q.Push(v)
+		s.Push(v)
+	}
+	if v, _ := q.Peek(); v != "a" || q.Len() != 3 {
+		t.Errorf("wrong queue peek %q", v)
+	}
+	if v, _ := s.Peek(); v != "c" || s.Len() != 3 {
+		t.Errorf("wrong stack peek %q", v)
+	}
+	qs, ss := "", ""
+	for q.Len() > 0 {
+		v, _ := q.Pop()
+		qs += v
+	}
+	for s.Len() > 0 {
+		v, _ := s.Pop()
+		ss += v
+	}
+	if qs != "abc" || ss != "cba" {
+		t.Errorf("wrong order: queue %q, stack %q", qs, ss)
+	}
+	if _, ok := q.Pop(); ok {
+		t.Error("empty queue should pop nothing")
+	}
+	if _, ok := s.Pop(); ok {
+		t.Error("empty stack should pop nothing")
+	}
+}
+
+func BenchmarkQueue(b *testing.B) {
+	q := Queue[int]{}
+	for i := 0; i < b.N; i++ {
+		q.Push(i)
+		q.Pop()
+	}
+}
+
+func BenchmarkSyncQueue(b *testing.B) {
+	q := SyncQueue[int]{}
+	for i := 0; i < b.N; i++ {
+		q.Push(i)
+		q.Pop()
+	}
+}
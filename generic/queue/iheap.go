@@ -0,0 +1,197 @@
+package queue
+
+// Ordered is a type with < defined on its values
+type Ordered interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64 | ~string
+}
+
+// PriorityQueue is a min-priority queue of non-negative integer keys,
+// such as node IDs, where every key is present at most once
+type PriorityQueue[P Ordered] interface {
+	// Len returns number of keys in the queue
+	Len() int
+	// Push adds the key with the priority or changes priority of the present key
+	Push(key int, prio P)
+	// Pop removes and returns the key with the least priority,
+	// false if the queue is empty
+	Pop() (key int, prio P, ok bool)
+	// DecreaseKey lowers priority of the present key, it returns false
+	// if the key is absent or its priority is not greater than prio
+	DecreaseKey(key int, prio P) bool
+	// Contains reports whether the key is in the queue
+	Contains(key int) bool
+	// Remove removes the key, false if it is absent
+	Remove(key int) bool
+}
+
+// IndexedHeap is a binary min-heap which knows position of every key,
+// so that a key can be found, reprioritized or removed in O(log n)
+// instead of being pushed again. Memory is proportional to the largest key.
+// IndexedHeap is not safe for concurrent use. The zero value is an empty
+// heap ready to use.
+type IndexedHeap[P Ordered] struct {
+	keys  []int
+	prios []P
+	pos   []int // position of key in keys plus one, 0 for absent keys
+}
+
+// NewIndexedHeap returns a heap preallocated for keys less than size
+func NewIndexedHeap[P Ordered](size int) *IndexedHeap[P] {
+	return &IndexedHeap[P]{pos: make([]int, size)}
+}
+
+// Len returns number of keys in the heap
+func (h *IndexedHeap[P]) Len() int {
+	return len(h.keys)
+}
+
+// Contains reports whether the key is in the heap
+func (h *IndexedHeap[P]) Contains(key int) bool {
+	return key >= 0 && key < len(h.pos) && h.pos[key] != 0
+}
+
+// Priority returns priority of the key, false if it is absent
+func (h *IndexedHeap[P]) Priority(key int) (P, bool) {
+	if !h.Contains(key) {
+		var zero P
+		return zero, false
+	}
+	return h.prios[h.pos[key]-1], true
+}
+
+// Push adds the key with the priority or changes priority of the present key.
+// It panics if the key is negative.
+func (h *IndexedHeap[P]) Push(key int, prio P) {
+	if key < 0 {
+		panic("queue: negative key")
+	}
+	if h.Contains(key) {
+		i := h.pos[key] - 1
+		old := h.prios[i]
+		h.prios[i] = prio
+		if prio < old {
+			h.up(i)
+		} else {
+			h.down(i)
+		}
+		return
+	}
+	if key >= len(h.pos) {
+		size := 2 * len(h.pos)
+		if size <= key {
+			size = key + 1
+		}
+		pos := make([]int, size)
+		copy(pos, h.pos)
+		h.pos = pos
+	}
+	h.keys = append(h.keys, key)
+	h.prios = append(h.prios, prio)
+	h.pos[key] = len(h.keys)
+	h.up(len(h.keys) - 1)
+}
+
+// Peek returns the key with the least priority without removing it,
+// false if the heap is empty
+func (h *IndexedHeap[P]) Peek() (key int, prio P, ok bool) {
+	if len(h.keys) == 0 {
+		return 0, prio, false
+	}
+	return h.keys[0], h.prios[0], true
+}
+
+// Pop removes and returns the key with the least priority, false if the heap is empty
+func (h *IndexedHeap[P]) Pop() (key int, prio P, ok bool) {
+	if len(h.keys) == 0 {
+		return 0, prio, false
+	}
+	key, prio = h.keys[0], h.prios[0]
+	h.removeAt(0)
+	return key, prio, true
+}
+
+// DecreaseKey lowers priority of the present key, it returns false
+// if the key is absent or its priority is not greater than prio
+func (h *IndexedHeap[P]) DecreaseKey(key int, prio P) bool {
+	if !h.Contains(key) {
+		return false
+	}
+	i := h.pos[key] - 1
+	if !(prio < h.prios[i]) {
+		return false
+	}
+	h.prios[i] = prio
+	h.up(i)
+	return true
+}
+
+// Remove removes the key, false if it is absent
+func (h *IndexedHeap[P]) Remove(key int) bool {
+	if !h.Contains(key) {
+		return false
+	}
+	h.removeAt(h.pos[key] - 1)
+	return true
+}
+
+// Clear removes all keys keeping allocated memory
+func (h *IndexedHeap[P]) Clear() {
+	for _, k := range h.keys {
+		h.pos[k] = 0
+	}
+	h.keys = h.keys[:0]
+	h.prios = h.prios[:0]
+}
+
+func (h *IndexedHeap[P]) removeAt(i int) {
+	last := len(h.keys) - 1
+	h.pos[h.keys[i]] = 0
+	if i != last {
+		h.keys[i], h.prios[i] = h.keys[last], h.prios[last]
+		h.pos[h.keys[i]] = i + 1
+	}
+	h.keys = h.keys[:last]
+	h.prios = h.prios[:last]
+	if i != last {
+		h.down(i)
+		h.up(i)
+	}
+}
+
+func (h *IndexedHeap[P]) swap(i, j int) {
+	h.keys[i], h.keys[j] = h.keys[j], h.keys[i]
+	h.prios[i], h.prios[j] = h.prios[j], h.prios[i]
+	h.pos[h.keys[i]] = i + 1
+	h.pos[h.keys[j]] = j + 1
+}
+
+func (h *IndexedHeap[P]) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !(h.prios[i] < h.prios[parent]) {
+			break
+		}
+		h.swap(i, parent)
+		i = parent
+	}
+}
+
+func (h *IndexedHeap[P]) down(i int) {
+	n := len(h.keys)
+	for {
+		least := i
+		if l := 2*i + 1; l < n && h.prios[l] < h.prios[least] {
+			least = l
+		}
+		if r := 2*i + 2; r < n && h.prios[r] < h.prios[least] {
+			least = r
+		}
+		if least == i {
+			return
+		}
+		h.swap(i, least)
+		i = least
+	}
+}
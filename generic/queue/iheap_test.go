@@ -0,0 +1,137 @@
+package queue
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+var (
+	_ PriorityQueue[int]     = &IndexedHeap[int]{}
+	_ PriorityQueue[float32] = &PairingHeap[float32]{}
+)
+
+// checkPriorityQueue runs random operations on q comparing it with a map
+func checkPriorityQueue(t *testing.T, q PriorityQueue[int]) {
+	rnd := rand.New(rand.NewSource(1))
+	model := map[int]int{}
+	popMin := func() (int, int) {
+		keys := make([]int, 0, len(model))
+		for k := range model {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if model[keys[i]] != model[keys[j]] {
+				return model[keys[i]] < model[keys[j]]
+			}
+			return keys[i] < keys[j]
+		})
+		return keys[0], model[keys[0]]
+	}
+
+	for i := 0; i < 5000; i++ {
+		key := rnd.Intn(300)
+		prio := rnd.Intn(1000)
+		switch op := rnd.Intn(10); {
+		case op < 4:
+			q.Push(key, prio)
+			model[key] = prio
+		case op < 6:
+			old, ok := model[key]
+			changed := q.DecreaseKey(key, prio)
+			if changed != (ok && prio < old) {
+				t.Fatalf("#%d: DecreaseKey(%d, %d) = %v, old priority %d %v", i, key, prio, changed, old, ok)
+			}
+			if changed {
+				model[key] = prio
+			}
+		case op < 7:
+			_, ok := model[key]
+			if q.Remove(key) != ok {
+				t.Fatalf("#%d: Remove(%d) != %v", i, key, ok)
+			}
+			delete(model, key)
+		default:
+			k, p, ok := q.Pop()
+			if ok != (len(model) > 0) {
+				t.Fatalf("#%d: Pop() ok = %v with %d keys", i, ok, len(model))
+			}
+			if !ok {
+				continue
+			}
+			_, minPrio := popMin()
+			if p != minPrio || model[k] != p {
+				t.Fatalf("#%d: Pop() = %d %d, least priority is %d", i, k, p, minPrio)
+			}
+			delete(model, k)
+		}
+		if q.Len() != len(model) {
+			t.Fatalf("#%d: wrong length %d, expected %d", i, q.Len(), len(model))
+		}
+		if _, ok := model[key]; q.Contains(key) != ok {
+			t.Fatalf("#%d: Contains(%d) != %v", i, key, ok)
+		}
+	}
+
+	// drain in order
+	last := -1
+	for q.Len() > 0 {
+		_, p, _ := q.Pop()
+		if p < last {
+			t.Fatalf("priorities out of order: %d after %d", p, last)
+		}
+		last = p
+	}
+	if q.Contains(-1) || q.Remove(1<<20) || q.DecreaseKey(1<<20, 0) {
+		t.Errorf("absent keys are found")
+	}
+}
+
+func TestIndexedHeap(t *testing.T) {
+	checkPriorityQueue(t, &IndexedHeap[int]{})
+	checkPriorityQueue(t, NewIndexedHeap[int](10))
+
+	h := NewIndexedHeap[string](0)
+	h.Push(3, "c")
+	h.Push(1, "a")
+	h.Push(2, "b")
+	if k, p, _ := h.Peek(); k != 1 || p != "a" || h.Len() != 3 {
+		t.Errorf("wrong peek %d %q", k, p)
+	}
+	h.Push(3, "0") // increase and decrease through Push
+	h.Push(1, "z")
+	if p, ok := h.Priority(1); !ok || p != "z" {
+		t.Errorf("wrong priority %q", p)
+	}
+	order := ""
+	for h.Len() > 0 {
+		_, p, _ := h.Pop()
+		order += p
+	}
+	if order != "0bz" {
+		t.Errorf("wrong order %q", order)
+	}
+
+	h.Push(5, "x")
+	h.Clear()
+	if h.Len() != 0 || h.Contains(5) {
+		t.Errorf("heap is not cleared")
+	}
+}
+
+func BenchmarkIndexedHeap(b *testing.B) {
+	benchPriorityQueue(b, NewIndexedHeap[float64](1024))
+}
+
+func benchPriorityQueue(b *testing.B, q PriorityQueue[float64]) {
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < b.N; i++ {
+		key := rnd.Intn(1024)
+		if !q.DecreaseKey(key, rnd.Float64()) {
+			q.Push(key, rnd.Float64())
+		}
+		if i%4 == 0 {
+			q.Pop()
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package queue
+
+// PairingHeap is a min-heap of keys with amortized O(1) Push and DecreaseKey
+// and O(log n) Pop, which suits algorithms doing many more decreases than
+// pops, like Dijkstra's on dense graphs. Like IndexedHeap it keeps every key
+// at most once and its memory is proportional to the largest key.
+// PairingHeap is not safe for concurrent use. The zero value is an empty
+// heap ready to use.
+type PairingHeap[P Ordered] struct {
+	root  *pairingNode[P]
+	nodes []*pairingNode[P] // by key, nil for absent keys
+	n     int
+}
+
+type pairingNode[P Ordered] struct {
+	key     int
+	prio    P
+	child   *pairingNode[P]
+	sibling *pairingNode[P]
+	prev    *pairingNode[P] // parent for the first child, previous sibling otherwise
+}
+
+// Len returns number of keys in the heap
+func (h *PairingHeap[P]) Len() int {
+	return h.n
+}
+
+// Contains reports whether the key is in the heap
+func (h *PairingHeap[P]) Contains(key int) bool {
+	return key >= 0 && key < len(h.nodes) && h.nodes[key] != nil
+}
+
+// Priority returns priority of the key, false if it is absent
+func (h *PairingHeap[P]) Priority(key int) (P, bool) {
+	if !h.Contains(key) {
+		var zero P
+		return zero, false
+	}
+	return h.nodes[key].prio, true
+}
+
+// Push adds the key with the priority or changes priority of the present key.
+// It panics if the key is negative.
+func (h *PairingHeap[P]) Push(key int, prio P) {
+	if key < 0 {
+		panic("queue: negative key")
+	}
+	if h.Contains(key) {
+		if !h.DecreaseKey(key, prio) && prio != h.nodes[key].prio {
+			h.Remove(key)
+			h.Push(key, prio)
+		}
+		return
+	}
+	if key >= len(h.nodes) {
+		size := 2 * len(h.nodes)
+		if size <= key {
+			size = key + 1
+		}
+		nodes := make([]*pairingNode[P], size)
+		copy(nodes, h.nodes)
+		h.nodes = nodes
+	}
+	n := &pairingNode[P]{key: key, prio: prio}
+	h.nodes[key] = n
+	h.root = meld(h.root, n)
+	h.n++
+}
+
+// Peek returns the key with the least priority without removing it,
+// false if the heap is empty
+func (h *PairingHeap[P]) Peek() (key int, prio P, ok bool) {
+	if h.root == nil {
+		return 0, prio, false
+	}
+	return h.root.key, h.root.prio, true
+}
+
+// Pop removes and returns the key with the least priority, false if the heap is empty
+func (h *PairingHeap[P]) Pop() (key int, prio P, ok bool) {
+	r := h.root
+	if r == nil {
+		return 0, prio, false
+	}
+	h.root = mergePairs(r.child)
+	h.nodes[r.key] = nil
+	h.n--
+	return r.key, r.prio, true
+}
+
+// DecreaseKey lowers priority of the present key, it returns false
+// if the key is absent or its priority is not greater than prio
+func (h *PairingHeap[P]) DecreaseKey(key int, prio P) bool {
+	if !h.Contains(key) {
+		return false
+	}
+	n := h.nodes[key]
+	if !(prio < n.prio) {
+		return false
+	}
+	n.prio = prio
+	if n != h.root {
+		detach(n)
+		h.root = meld(h.root, n)
+	}
+	return true
+}
+
+// Remove removes the key, false if it is absent
+func (h *PairingHeap[P]) Remove(key int) bool {
+	if !h.Contains(key) {
+		return false
+	}
+	n := h.nodes[key]
+	if n == h.root {
+		h.Pop()
+		return true
+	}
+	detach(n)
+	h.root = meld(h.root, mergePairs(n.child))
+	h.nodes[key] = nil
+	h.n--
+	return true
+}
+
+// meld joins two heap-ordered trees, roots of both must have no siblings
+func meld[P Ordered](a, b *pairingNode[P]) *pairingNode[P] {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if b.prio < a.prio {
+		a, b = b, a
+	}
+	b.sibling = a.child
+	if a.child != nil {
+		a.child.prev = b
+	}
+	b.prev = a
+	a.child = b
+	return a
+}
+
+// detach cuts the subtree of n from its parent
+func detach[P Ordered](n *pairingNode[P]) {
+	if n.prev.child == n {
+		n.prev.child = n.sibling
+	} else {
+		n.prev.sibling = n.sibling
+	}
+	if n.sibling != nil {
+		n.sibling.prev = n.prev
+	}
+	n.prev, n.sibling = nil, nil
+}
+
+// mergePairs melds the list of siblings into one tree in two passes:
+// pairs from left to right and then the results from right to left
+func mergePairs[P Ordered](first *pairingNode[P]) *pairingNode[P] {
+	var pairs []*pairingNode[P]
+	for first != nil {
+		a := first
+		b := a.sibling
+		if b == nil {
+			first = nil
+		} else {
+			first = b.sibling
+			b.prev, b.sibling = nil, nil
+		}
+		a.prev, a.sibling = nil, nil
+		pairs = append(pairs, meld(a, b))
+	}
+	var root *pairingNode[P]
+	for i := len(pairs) - 1; i >= 0; i-- {
+		root = meld(pairs[i], root)
+	}
+	return root
+}
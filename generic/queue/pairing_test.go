@@ -0,0 +1,41 @@
+package queue
+
+import (
+	"testing"
+)
+
+func TestPairingHeap(t *testing.T) {
+	checkPriorityQueue(t, &PairingHeap[int]{})
+
+	h := PairingHeap[float64]{}
+	for i, p := range []float64{5, 3, 8, 1, 9, 2} {
+		h.Push(i, p)
+	}
+	h.DecreaseKey(4, 0) // 9 -> 0
+	h.Remove(3)         // 1
+	h.Push(0, 10)       // 5 -> 10
+	if p, ok := h.Priority(0); !ok || p != 10 {
+		t.Errorf("wrong priority %v", p)
+	}
+	keys := []int{}
+	for h.Len() > 0 {
+		k, _, _ := h.Pop()
+		keys = append(keys, k)
+	}
+	expected := []int{4, 5, 1, 2, 0}
+	if len(keys) != len(expected) {
+		t.Fatalf("wrong keys %v", keys)
+	}
+	for i := range keys {
+		if keys[i] != expected[i] {
+			t.Fatalf("wrong keys %v, expected %v", keys, expected)
+		}
+	}
+	if _, _, ok := h.Peek(); ok {
+		t.Errorf("empty heap has top")
+	}
+}
+
+func BenchmarkPairingHeap(b *testing.B) {
+	benchPriorityQueue(b, &PairingHeap[float64]{})
+}
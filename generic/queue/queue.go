@@ -1,46 +1,28 @@
 package queue
 
-import (
-	"sync"
-)
-
-// Queue is a thread safe LIFO queue
-type Queue struct {
-	items []interface{}
-	lock  sync.RWMutex
+// AnyQueue is a thread safe FIFO queue of arbitrary items.
+//
+// Deprecated: AnyQueue is the untyped Queue of earlier versions,
+// use Queue or SyncQueue.
+type AnyQueue struct {
+	q SyncQueue[interface{}]
 }
 
 // Len returns number of items in the queue
-func (q *Queue) Len() int {
-	q.lock.RLock()
-	defer q.lock.RUnlock()
-
-	return len(q.items)
+func (q *AnyQueue) Len() int {
+	return q.q.Len()
 }
 
-// Push adds item to the end of the queue. Nils are ignored.
-func (q *Queue) Push(n interface{}) int {
+// Push adds item to the end of the queue and returns the new length. Nils are ignored.
+func (q *AnyQueue) Push(n interface{}) int {
 	if n == nil {
-		return len(q.items)
+		return q.q.Len()
 	}
-
-	q.lock.Lock()
-	defer q.lock.Unlock()
-
-	q.items = append(q.items, n)
-	return len(q.items)
+	return q.q.Push(n)
 }
 
 // Pop returns the first item and removes it from the queue. Returns nil only if empty.
-func (q *Queue) Pop() interface{} {
-	q.lock.Lock()
-	defer q.lock.Unlock()
-
-	if len(q.items) == 0 {
-		return nil
-	}
-
-	first := q.items[0]
-	q.items = q.items[1:]
-	return first
+func (q *AnyQueue) Pop() interface{} {
+	item, _ := q.q.Pop()
+	return item
 }
@@ -9,7 +9,7 @@ type qitem struct {
 }
 
 func TestQueueNew(t *testing.T) {
-	s := Queue{}
+	s := AnyQueue{}
 	if s.Len() != 0 {
 		t.Error("empty queue shold have length of zero")
 	}
@@ -26,7 +26,7 @@ func TestQueuePushPop(t *testing.T) {
 	b := &qitem{}
 	c := &qitem{}
 
-	s := Queue{}
+	s := AnyQueue{}
 
 	s.Push(a)
 	if s.Len() != 1 {
@@ -58,7 +58,7 @@ func TestQueuePushPop(t *testing.T) {
 }
 
 func TestQueuePushNil(t *testing.T) {
-	s := Queue{}
+	s := AnyQueue{}
 	a := &qitem{}
 	b := &qitem{}
 	s.Push(a)
@@ -74,7 +74,7 @@ func TestQueuePushNil(t *testing.T) {
 }
 
 func TestQueueConcurency(t *testing.T) {
-	s := Queue{}
+	s := AnyQueue{}
 	count := 100
 	nodes := make([]*qitem, count)
 	for i := 0; i < count; i++ {
@@ -104,7 +104,7 @@ func TestQueueConcurency(t *testing.T) {
 
 func BenchmarkQueuePush(b *testing.B) {
 	b.StopTimer()
-	s := Queue{}
+	s := AnyQueue{}
 	b.StartTimer()
 	for i := 0; i < b.N; i++ {
 		n := &qitem{i: i}
@@ -114,7 +114,7 @@ func BenchmarkQueuePush(b *testing.B) {
 
 func BenchmarkQueuePop(b *testing.B) {
 	b.StopTimer()
-	s := Queue{}
+	s := AnyQueue{}
 	for i := 0; i < b.N; i++ {
 		n := &qitem{i: i}
 		s.Push(n)
@@ -1,46 +1,28 @@
 package queue
 
-import (
-	"sync"
-)
-
-// Stack is a thread safe LIFO stack
-type Stack struct {
-	items []interface{}
-	lock  sync.RWMutex
+// AnyStack is a thread safe LIFO stack of arbitrary items.
+//
+// Deprecated: AnyStack is the untyped Stack of earlier versions,
+// use Stack or SyncStack.
+type AnyStack struct {
+	q SyncStack[interface{}]
 }
 
 // Len returns number of nodes on the stack
-func (q *Stack) Len() int {
-	q.lock.RLock()
-	defer q.lock.RUnlock()
-
-	return len(q.items)
+func (q *AnyStack) Len() int {
+	return q.q.Len()
 }
 
-// Push adds node to the stack. Nils are ignored.
-func (q *Stack) Push(n interface{}) int {
+// Push adds node to the stack and returns the new length. Nils are ignored.
+func (q *AnyStack) Push(n interface{}) int {
 	if n == nil {
-		return len(q.items)
+		return q.q.Len()
 	}
-	q.lock.Lock()
-	defer q.lock.Unlock()
-
-	q.items = append(q.items, n)
-	return len(q.items)
+	return q.q.Push(n)
 }
 
 // Pop returns the last node and removes it from the stack. Returns nil only if empty.
-func (q *Stack) Pop() interface{} {
-	q.lock.Lock()
-	defer q.lock.Unlock()
-
-	i := len(q.items) - 1
-	if i < 0 {
-		return nil
-	}
-
-	last := q.items[i]
-	q.items = q.items[:i]
-	return last
+func (q *AnyStack) Pop() interface{} {
+	item, _ := q.q.Pop()
+	return item
 }
@@ -9,7 +9,7 @@ type sitem struct {
 }
 
 func TestStackNew(t *testing.T) {
-	s := Stack{}
+	s := AnyStack{}
 	if s.Len() != 0 {
 		t.Error("empty stack shold have length of zero")
 	}
@@ -26,7 +26,7 @@ func TestStackPushPop(t *testing.T) {
 	b := &sitem{}
 	c := &sitem{}
 
-	s := Stack{}
+	s := AnyStack{}
 
 	s.Push(a)
 	if s.Len() != 1 {
@@ -58,7 +58,7 @@ func TestStackPushPop(t *testing.T) {
 }
 
 func TestStackPushNil(t *testing.T) {
-	s := Stack{}
+	s := AnyStack{}
 	a := &sitem{}
 	b := &sitem{}
 	s.Push(a)
@@ -74,7 +74,7 @@ func TestStackPushNil(t *testing.T) {
 }
 
 func TestStackConcurency(t *testing.T) {
-	s := Stack{}
+	s := AnyStack{}
 	count := 100
 	nodes := make([]*sitem, count)
 	for i := 0; i < count; i++ {
@@ -104,7 +104,7 @@ func TestStackConcurency(t *testing.T) {
 
 func BenchmarkStackPush(b *testing.B) {
 	b.StopTimer()
-	s := Stack{}
+	s := AnyStack{}
 	b.StartTimer()
 	for i := 0; i < b.N; i++ {
 		n := &sitem{i: i}
@@ -114,7 +114,7 @@ func BenchmarkStackPush(b *testing.B) {
 
 func BenchmarkStackPop(b *testing.B) {
 	b.StopTimer()
-	s := Stack{}
+	s := AnyStack{}
 	for i := 0; i < b.N; i++ {
 		n := &sitem{i: i}
 		s.Push(n)
@@ -0,0 +1,86 @@
+package queue
+
+import "sync"
+
+// SyncDeque is a Deque safe for concurrent use
+type SyncDeque[T any] struct {
+	d    Deque[T]
+	lock sync.Mutex
+}
+
+// Len returns number of items in the deque
+func (d *SyncDeque[T]) Len() int {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.d.Len()
+}
+
+// PushBack adds the item to the back of the deque and returns the new length
+func (d *SyncDeque[T]) PushBack(v T) int {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.d.PushBack(v)
+	return d.d.Len()
+}
+
+// PushFront adds the item to the front of the deque and returns the new length
+func (d *SyncDeque[T]) PushFront(v T) int {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.d.PushFront(v)
+	return d.d.Len()
+}
+
+// PopFront removes and returns the front item, false if the deque is empty
+func (d *SyncDeque[T]) PopFront() (T, bool) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.d.PopFront()
+}
+
+// PopBack removes and returns the back item, false if the deque is empty
+func (d *SyncDeque[T]) PopBack() (T, bool) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.d.PopBack()
+}
+
+// SyncQueue is a Queue safe for concurrent use
+type SyncQueue[T any] struct {
+	d SyncDeque[T]
+}
+
+// Len returns number of items in the queue
+func (q *SyncQueue[T]) Len() int {
+	return q.d.Len()
+}
+
+// Push adds the item to the end of the queue and returns the new length
+func (q *SyncQueue[T]) Push(v T) int {
+	return q.d.PushBack(v)
+}
+
+// Pop removes and returns the first item, false if the queue is empty
+func (q *SyncQueue[T]) Pop() (T, bool) {
+	return q.d.PopFront()
+}
+
+// SyncStack is a Stack safe for concurrent use
+type SyncStack[T any] struct {
+	d SyncDeque[T]
+}
+
+// Len returns number of items on the stack
+func (s *SyncStack[T]) Len() int {
+	return s.d.Len()
+}
+
+// Push adds the item on top of the stack and returns the new length
+func (s *SyncStack[T]) Push(v T) int {
+	return s.d.PushBack(v)
+}
+
+// Pop removes and returns the top item, false if the stack is empty
+func (s *SyncStack[T]) Pop() (T, bool) {
+	return s.d.PopBack()
+}
@@ -0,0 +1,77 @@
+package queue
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSyncDeque(t *testing.T) {
+	d := SyncDeque[int]{}
+	const workers, count = 8, 1000
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < count; i++ {
+				if i%2 == 0 {
+					d.PushBack(w*count + i)
+				} else {
+					d.PushFront(w*count + i)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	if d.Len() != workers*count {
+		t.Fatalf("wrong length %d", d.Len())
+	}
+
+	seen := make([]bool, workers*count)
+	var lock sync.Mutex
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for {
+				pop := d.PopFront
+				if w%2 == 0 {
+					pop = d.PopBack
+				}
+				v, ok := pop()
+				if !ok {
+					return
+				}
+				lock.Lock()
+				if seen[v] {
+					t.Errorf("item %d popped twice", v)
+				}
+				seen[v] = true
+				lock.Unlock()
+			}
+		}(w)
+	}
+	wg.Wait()
+	for v, ok := range seen {
+		if !ok {
+			t.Errorf("item %d is lost", v)
+		}
+	}
+}
+
+func TestSyncQueueStack(t *testing.T) {
+	q := SyncQueue[int]{}
+	s := SyncStack[int]{}
+	for i := 1; i <= 3; i++ {
+		if q.Push(i) != i || s.Push(i) != i {
+			t.Errorf("push should return the new length %d", i)
+		}
+	}
+	if v, _ := q.Pop(); v != 1 || q.Len() != 2 {
+		t.Errorf("wrong queue pop %d", v)
+	}
+	if v, _ := s.Pop(); v != 3 || s.Len() != 2 {
+		t.Errorf("wrong stack pop %d", v)
+	}
+}
@@ -0,0 +1,129 @@
+package gexf
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Namespace is the GEXF 1.3 XML namespace
+const Namespace = "http://gexf.net/1.3"
+
+// Data holds attribute values by attribute title. Values are typed after
+// the attribute declaration: bool for boolean, int for integer, int64 for
+// long, float32 for float, float64 for double and string for other types.
+type Data map[string]interface{}
+
+// Attribute is a GEXF attribute declaration
+type Attribute struct {
+	ID      string
+	Class   string // node or edge
+	Title   string
+	Type    string
+	Default interface{}
+}
+
+type xmlGEXF struct {
+	XMLName xml.Name  `xml:"gexf"`
+	Xmlns   string    `xml:"xmlns,attr,omitempty"`
+	Version string    `xml:"version,attr,omitempty"`
+	Meta    *xmlMeta  `xml:"meta"`
+	Graph   *xmlGraph `xml:"graph"`
+}
+
+type xmlMeta struct {
+	Creator     string `xml:"creator,omitempty"`
+	Description string `xml:"description,omitempty"`
+}
+
+type xmlGraph struct {
+	DefaultEdgeType string          `xml:"defaultedgetype,attr,omitempty"`
+	Mode            string          `xml:"mode,attr,omitempty"`
+	Attributes      []xmlAttributes `xml:"attributes"`
+	Nodes           []xmlNode       `xml:"nodes>node"`
+	Edges           []xmlEdge       `xml:"edges>edge"`
+}
+
+type xmlAttributes struct {
+	Class string    `xml:"class,attr"`
+	Attrs []xmlAttr `xml:"attribute"`
+}
+
+type xmlAttr struct {
+	ID      string  `xml:"id,attr"`
+	Title   string  `xml:"title,attr,omitempty"`
+	Type    string  `xml:"type,attr"`
+	Default *string `xml:"default"`
+}
+
+type xmlNode struct {
+	ID        string        `xml:"id,attr"`
+	Label     string        `xml:"label,attr,omitempty"`
+	AttValues []xmlAttValue `xml:"attvalues>attvalue"`
+	Nodes     []xmlNode     `xml:"nodes>node"` // hierarchy
+}
+
+type xmlEdge struct {
+	ID        string        `xml:"id,attr"`
+	Source    string        `xml:"source,attr"`
+	Target    string        `xml:"target,attr"`
+	Type      string        `xml:"type,attr,omitempty"`
+	Weight    string        `xml:"weight,attr,omitempty"`
+	Label     string        `xml:"label,attr,omitempty"`
+	AttValues []xmlAttValue `xml:"attvalues>attvalue"`
+}
+
+type xmlAttValue struct {
+	For   string `xml:"for,attr,omitempty"`
+	ID    string `xml:"id,attr,omitempty"` // GEXF 1.1 and older
+	Value string `xml:"value,attr"`
+}
+
+// typeOf returns GEXF type of the value
+func typeOf(v interface{}) (string, error) {
+	switch v.(type) {
+	case bool:
+		return "boolean", nil
+	case int:
+		return "integer", nil
+	case int64:
+		return "long", nil
+	case float32:
+		return "float", nil
+	case float64:
+		return "double", nil
+	case string:
+		return "string", nil
+	}
+	return "", fmt.Errorf("unsupported attribute type %T", v)
+}
+
+// parseValue converts the text to a value of the GEXF type,
+// types without Go counterpart are kept as strings
+func parseValue(typ, s string) (interface{}, error) {
+	switch typ {
+	case "boolean":
+		return strconv.ParseBool(strings.TrimSpace(s))
+	case "integer":
+		return strconv.Atoi(strings.TrimSpace(s))
+	case "long":
+		return strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	case "float":
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 32)
+		return float32(f), err
+	case "double":
+		return strconv.ParseFloat(strings.TrimSpace(s), 64)
+	}
+	return s, nil
+}
+
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,235 @@
+package gexf
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/iimos/gorka"
+	"github.com/iimos/gorka/gralang"
+	"github.com/iimos/gorka/types"
+)
+
+func TestParse(t *testing.T) {
+	doc := `<?xml version="1.0" encoding="UTF-8"?>
+<gexf xmlns="http://gexf.net/1.3" version="1.3">
+  <meta lastmodifieddate="2020-01-01">
+    <creator>Gephi 0.9</creator>
+    <description>sample</description>
+  </meta>
+  <graph mode="static" defaultedgetype="directed">
+    <attributes class="node">
+      <attribute id="0" title="kind" type="string"><default>leaf</default></attribute>
+      <attribute id="1" title="size" type="integer"/>
+    </attributes>
+    <attributes class="edge">
+      <attribute id="0" title="weight" type="double"/>
+    </attributes>
+    <nodes>
+      <node id="0" label="root">
+        <attvalues><attvalue for="0" value="root"/><attvalue for="1" value="3"/></attvalues>
+        <nodes>
+          <node id="0.1" label="child"/>
+        </nodes>
+      </node>
+      <node id="1"/>
+    </nodes>
+    <edges>
+      <edge id="0" source="0" target="1" weight="2.5"/>
+      <edge id="1" source="1" target="0.1" type="undirected"/>
+      <edge id="2" source="0.1" target="0">
+        <attvalues><attvalue id="0" value="4"/></attvalues>
+      </edge>
+    </edges>
+  </graph>
+</gexf>`
+
+	g := gorka.New()
+	d := NewDecoder(strings.NewReader(doc))
+	nodes := map[string]Data{}
+	d.OnNode(func(n types.Node, attrs Data) {
+		nodes[n.Label()] = attrs
+	})
+	edges := 0
+	d.OnEdge(func(e types.Edge, attrs Data) {
+		edges++
+	})
+	if err := d.Decode(g); err != nil {
+		t.Fatalf("decode error: %s", err)
+	}
+
+	if g.NodesCount() != 3 {
+		t.Errorf("wrong nodes count: %d", g.NodesCount())
+	}
+	if g.EdgesCount() != 4 {
+		t.Errorf("wrong edges count: %d", g.EdgesCount())
+	}
+	if edges != 3 {
+		t.Errorf("wrong edge callbacks count: %d", edges)
+	}
+
+	type e struct {
+		a, b string
+		w    float32
+	}
+	for _, c := range []e{{"root", "1", 2.5}, {"1", "child", 1}, {"child", "1", 1}, {"child", "root", 4}} {
+		a, _ := g.NodeByLabel(c.a)
+		found := false
+		g.NodeEdgeIter(a, func(edge types.Edge) bool {
+			if edge.Dst().Label() == c.b {
+				found = edge.Wieght() == c.w
+			}
+			return true
+		})
+		if !found {
+			t.Errorf("no edge %s->%s of weight %f", c.a, c.b, c.w)
+		}
+	}
+
+	if nodes["root"]["kind"] != "root" || nodes["root"]["size"] != 3 {
+		t.Errorf("wrong attrs of root: %v", nodes["root"])
+	}
+	if nodes["child"]["kind"] != "leaf" {
+		t.Errorf("default attr is not applied to child: %v", nodes["child"])
+	}
+
+	h := d.Header()
+	if !h.Directed || h.Mode != "static" || h.Creator != "Gephi 0.9" || h.Description != "sample" {
+		t.Errorf("wrong header: %+v", h)
+	}
+	if len(d.Attributes()) != 3 || d.Attributes()[2].Class != "edge" {
+		t.Errorf("wrong attributes: %+v", d.Attributes())
+	}
+}
+
+func TestParseUndirectedDefault(t *testing.T) {
+	g := gorka.New()
+	err := Parse(g, `<gexf><graph><nodes><node id="a"/><node id="b"/></nodes>
+		<edges><edge id="0" source="a" target="b"/></edges></graph></gexf>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.EdgesCount() != 2 {
+		t.Errorf("expected edge in both directions, got %d edges", g.EdgesCount())
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []string{
+		"",
+		"<gexf></gexf>",
+		"<gexf><graph><nodes><node/></nodes></graph></gexf>",
+		`<gexf><graph><nodes><node id="a"/><node id="a"/></nodes></graph></gexf>`,
+		`<gexf><graph><edges><edge source="a"/></edges></graph></gexf>`,
+		`<gexf><graph><nodes><node id="a"/></nodes><edges><edge source="a" target="b"/></edges></graph></gexf>`,
+		`<gexf><graph><nodes><node id="a"/></nodes><edges><edge source="a" target="a" weight="x"/></edges></graph></gexf>`,
+		`<gexf><graph><nodes><node id="a"><attvalues><attvalue for="0" value="1"/></attvalues></node></nodes></graph></gexf>`,
+		`<gexf><graph><attributes class="node"><attribute id="0" type="integer"/></attributes>
+			<nodes><node id="a"><attvalues><attvalue for="0" value="z"/></attvalues></node></nodes></graph></gexf>`,
+		`<gexf><graph><attributes class="node"><attribute id="0" type="integer"><default>z</default></attribute></attributes></graph></gexf>`,
+		`<gexf><graph><attributes class="edge"><attribute id="0" title="weight" type="boolean"/></attributes>
+			<nodes><node id="a"/></nodes><edges><edge source="a" target="a"><attvalues><attvalue for="0" value="true"/></attvalues></edge></edges></graph></gexf>`,
+		`<gexf><graph>`,
+	}
+	for i, text := range cases {
+		if err := Parse(gorka.New(), text); err == nil {
+			t.Errorf("#%d: '%s' parsed without error", i, text)
+		}
+	}
+	if err := Parse(nil, "<gexf/>"); err == nil {
+		t.Errorf("parse should not accept nil graphs")
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	src := gorka.New()
+	gralang.Parse(src, "a -> b c; b -- c")
+	a, _ := src.NodeByLabel("a")
+	b, _ := src.NodeByLabel("b")
+	c, _ := src.NodeByLabel("c")
+	d, _ := src.NewNode("<d&\"e\">")
+	src.AddEdge(a, b, 2.5)
+	src.AddEdge(c, d, 1)
+	src.NewNode("")
+
+	for _, undirected := range []bool{false, true} {
+		var s strings.Builder
+		opt := &Options{
+			Undirected: undirected,
+			Weights:    true,
+			Creator:    "gorka",
+			NodeAttrs: func(n types.Node) Data {
+				return Data{"len": len(n.Label()), "big": n.ID() > 2}
+			},
+			EdgeAttrs: func(e types.Edge) Data {
+				return Data{"name": e.String()}
+			},
+		}
+		if err := Write(&s, src, opt); err != nil {
+			t.Fatalf("undirected=%v: write error: %s", undirected, err)
+		}
+
+		g := gorka.New()
+		d := NewDecoder(strings.NewReader(s.String()))
+		d.OnNode(func(n types.Node, attrs Data) {
+			if attrs["big"] != (n.ID() > 2) {
+				t.Errorf("undirected=%v: wrong attrs of %s: %v", undirected, n, attrs)
+			}
+		})
+		d.OnEdge(func(e types.Edge, attrs Data) {
+			if _, ok := attrs["name"].(string); !ok {
+				t.Errorf("undirected=%v: wrong attrs of %s: %v", undirected, e, attrs)
+			}
+		})
+		if err := d.Decode(g); err != nil {
+			t.Errorf("undirected=%v: parse error: %s\n%s", undirected, err, s.String())
+			continue
+		}
+
+		if d.Header().Directed == undirected || d.Header().Creator != "gorka" {
+			t.Errorf("undirected=%v: wrong header %+v", undirected, d.Header())
+		}
+		if g.NodesCount() != src.NodesCount() {
+			t.Errorf("undirected=%v: wrong nodes count %d", undirected, g.NodesCount())
+		}
+		if _, ok := g.NodeByLabel("<d&\"e\">"); !ok {
+			t.Errorf("undirected=%v: label with markup is lost", undirected)
+		}
+		expected := src.EdgesCount()
+		if undirected {
+			expected = 8
+		}
+		if g.EdgesCount() != expected {
+			t.Errorf("undirected=%v: wrong edges count %d, expected %d", undirected, g.EdgesCount(), expected)
+		}
+
+		ga, _ := g.NodeByLabel("a")
+		gb, _ := g.NodeByLabel("b")
+		g.NodeEdgeIter(ga, func(e types.Edge) bool {
+			if e.Dst().ID() == gb.ID() && e.Wieght() != 2.5 {
+				t.Errorf("undirected=%v: wrong weight of a->b: %f", undirected, e.Wieght())
+			}
+			return true
+		})
+	}
+}
+
+func TestWriteTypeConflict(t *testing.T) {
+	g := gorka.New()
+	gralang.Parse(g, "a b")
+	err := Write(&strings.Builder{}, g, &Options{
+		NodeAttrs: func(n types.Node) Data {
+			if n.Label() == "a" {
+				return Data{"x": 1}
+			}
+			return Data{"x": "one"}
+		},
+	})
+	if err == nil {
+		t.Errorf("conflicting attribute types should fail")
+	}
+
+	err = Write(&strings.Builder{}, g, &Options{NodeAttrs: func(types.Node) Data { return Data{"x": []int{}} }})
+	if err == nil {
+		t.Errorf("unsupported attribute type should fail")
+	}
+}
@@ -0,0 +1,241 @@
+package gexf
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/iimos/gorka/internal/graphutil"
+	"github.com/iimos/gorka/types"
+)
+
+// Header describes the graph of a GEXF document
+type Header struct {
+	Directed    bool
+	Mode        string // static or dynamic
+	Creator     string
+	Description string
+}
+
+// Decoder reads a GEXF document into a Graph.
+//
+// Nodes are labeled with their labels, nodes without a label with their ids.
+// Nodes of the hierarchy are added to the same graph. Edge weight is taken
+// from the weight attribute, then from the "weight" attribute value,
+// otherwise it is 1. Undirected and mutual edges are added in both directions.
+type Decoder struct {
+	r      io.Reader
+	header Header
+	attrs  []Attribute
+	onNode func(n types.Node, attrs Data)
+	onEdge func(e types.Edge, attrs Data)
+}
+
+// NewDecoder returns a decoder that reads from r
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r}
+}
+
+// OnNode sets fn to be called for each node with its attributes, defaults included
+func (d *Decoder) OnNode(fn func(n types.Node, attrs Data)) {
+	d.onNode = fn
+}
+
+// OnEdge sets fn to be called for each edge with its attributes, defaults included.
+// For undirected edges it gets the edge from source to target.
+func (d *Decoder) OnEdge(fn func(e types.Edge, attrs Data)) {
+	d.onEdge = fn
+}
+
+// Header returns the graph description of the decoded document
+func (d *Decoder) Header() Header {
+	return d.header
+}
+
+// Attributes returns attribute declarations of the decoded document
+func (d *Decoder) Attributes() []Attribute {
+	return d.attrs
+}
+
+// Decode reads the document and fills the graph
+func (d *Decoder) Decode(g types.Graph) error {
+	if g == nil {
+		return errors.New("graph is empty")
+	}
+
+	var doc xmlGEXF
+	if err := xml.NewDecoder(d.r).Decode(&doc); err != nil {
+		return err
+	}
+	if doc.Graph == nil {
+		return errors.New("document has no graph")
+	}
+	xg := doc.Graph
+
+	d.header = Header{Directed: xg.DefaultEdgeType == "directed", Mode: xg.Mode}
+	if doc.Meta != nil {
+		d.header.Creator = doc.Meta.Creator
+		d.header.Description = doc.Meta.Description
+	}
+
+	d.attrs = nil
+	for _, xa := range xg.Attributes {
+		if xa.Class != "node" && xa.Class != "edge" {
+			continue
+		}
+		for _, x := range xa.Attrs {
+			a := Attribute{ID: x.ID, Class: xa.Class, Title: x.Title, Type: x.Type}
+			if a.Title == "" {
+				a.Title = a.ID
+			}
+			if x.Default != nil {
+				v, err := parseValue(a.Type, *x.Default)
+				if err != nil {
+					return fmt.Errorf("attribute %s: default: %s", a.ID, err)
+				}
+				a.Default = v
+			}
+			d.attrs = append(d.attrs, a)
+		}
+	}
+
+	r := reader{d: d, g: g, byID: graphutil.NewNodes(g)}
+	if err := r.nodes(xg.Nodes); err != nil {
+		return err
+	}
+
+	for _, xe := range xg.Edges {
+		if xe.Source == "" || xe.Target == "" {
+			return fmt.Errorf("edge %s: source and target are required", xe.ID)
+		}
+		src, ok := r.byID.Get(xe.Source)
+		if !ok {
+			return fmt.Errorf("edge %s: unknown source %s", xe.ID, xe.Source)
+		}
+		dst, ok := r.byID.Get(xe.Target)
+		if !ok {
+			return fmt.Errorf("edge %s: unknown target %s", xe.ID, xe.Target)
+		}
+		attrs, err := r.data("edge", xe.AttValues)
+		if err != nil {
+			return fmt.Errorf("edge %s: %s", xe.ID, err)
+		}
+
+		weight := float32(1)
+		if xe.Weight != "" {
+			f, err := strconv.ParseFloat(strings.TrimSpace(xe.Weight), 32)
+			if err != nil {
+				return fmt.Errorf("edge %s: wrong weight '%s'", xe.ID, xe.Weight)
+			}
+			weight = float32(f)
+		} else if v, ok := attrs["weight"]; ok {
+			if weight, ok = toWeight(v); !ok {
+				return fmt.Errorf("edge %s: weight is not a number: %v", xe.ID, v)
+			}
+		}
+
+		typ := xe.Type
+		if typ == "" {
+			typ = xg.DefaultEdgeType
+		}
+		e := g.AddEdge(src, dst, weight)
+		if typ != "directed" {
+			g.AddEdge(dst, src, weight)
+		}
+		if d.onEdge != nil {
+			d.onEdge(e, attrs)
+		}
+	}
+	return nil
+}
+
+// Parse parses GEXF document and fills the graph
+func Parse(g types.Graph, s string) error {
+	return NewDecoder(strings.NewReader(s)).Decode(g)
+}
+
+type reader struct {
+	d    *Decoder
+	g    types.Graph
+	byID *graphutil.Nodes
+}
+
+func (r *reader) nodes(list []xmlNode) error {
+	for i := range list {
+		xn := &list[i]
+		if xn.ID == "" {
+			return errors.New("node without id")
+		}
+		n, err := r.byID.Add(xn.ID, xn.Label)
+		if err != nil {
+			return err
+		}
+
+		attrs, err := r.data("node", xn.AttValues)
+		if err != nil {
+			return fmt.Errorf("node %s: %s", xn.ID, err)
+		}
+		if r.d.onNode != nil {
+			r.d.onNode(n, attrs)
+		}
+		if err := r.nodes(xn.Nodes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// data converts attribute values to attributes adding defaults of the class
+func (r *reader) data(class string, values []xmlAttValue) (Data, error) {
+	attrs := Data{}
+	for _, a := range r.d.attrs {
+		if a.Default != nil && a.Class == class {
+			attrs[a.Title] = a.Default
+		}
+	}
+	for _, xv := range values {
+		id := xv.For
+		if id == "" {
+			id = xv.ID
+		}
+		a := r.attribute(class, id)
+		if a == nil {
+			return nil, fmt.Errorf("undeclared attribute %s", id)
+		}
+		v, err := parseValue(a.Type, xv.Value)
+		if err != nil {
+			return nil, fmt.Errorf("attribute %s: %s", a.Title, err)
+		}
+		attrs[a.Title] = v
+	}
+	return attrs, nil
+}
+
+func (r *reader) attribute(class, id string) *Attribute {
+	for i := range r.d.attrs {
+		if a := &r.d.attrs[i]; a.Class == class && a.ID == id {
+			return a
+		}
+	}
+	return nil
+}
+
+func toWeight(v interface{}) (float32, bool) {
+	switch v := v.(type) {
+	case int:
+		return float32(v), true
+	case int64:
+		return float32(v), true
+	case float32:
+		return v, true
+	case float64:
+		return float32(v), true
+	case string:
+		w, err := strconv.ParseFloat(v, 32)
+		return float32(w), err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,151 @@
+package gexf
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/iimos/gorka/internal/graphutil"
+	"github.com/iimos/gorka/types"
+)
+
+// Options controls how a graph is written
+type Options struct {
+	// Undirected sets defaultedgetype="undirected", see graphutil.SkipOpposite
+	Undirected bool
+	// Weights writes edge weights
+	Weights bool
+	// Creator and Description are written to the meta element when not empty
+	Creator     string
+	Description string
+
+	// NodeAttrs returns attributes of the node, may be nil
+	NodeAttrs func(n types.Node) Data
+	// EdgeAttrs returns attributes of the edge, may be nil
+	EdgeAttrs func(e types.Edge) Data
+}
+
+// Write writes the graph as GEXF 1.3 document. Node ids are node IDs,
+// labels are written when not empty. Attributes are declared for every
+// title met in the data, all values of an attribute must have the same type.
+func Write(w io.Writer, g types.Graph, opt *Options) error {
+	if opt == nil {
+		opt = &Options{}
+	}
+
+	doc := xmlGEXF{Xmlns: Namespace, Version: "1.3"}
+	if opt.Creator != "" || opt.Description != "" {
+		doc.Meta = &xmlMeta{Creator: opt.Creator, Description: opt.Description}
+	}
+	graph := &xmlGraph{DefaultEdgeType: "directed", Mode: "static"}
+	if opt.Undirected {
+		graph.DefaultEdgeType = "undirected"
+	}
+	nodeAttrs := newAttrSet("node")
+	edgeAttrs := newAttrSet("edge")
+
+	var err error
+	g.NodeIter(func(n types.Node) bool {
+		xn := xmlNode{ID: strconv.Itoa(n.ID()), Label: n.Label()}
+		if opt.NodeAttrs != nil {
+			if xn.AttValues, err = nodeAttrs.values(opt.NodeAttrs(n)); err != nil {
+				return false
+			}
+		}
+		graph.Nodes = append(graph.Nodes, xn)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	g.NodeIter(func(n types.Node) bool {
+		for _, e := range graphutil.SortedEdges(g, n) {
+			d := e.Dst()
+			if opt.Undirected && graphutil.SkipOpposite(g, n, d) {
+				continue
+			}
+			xe := xmlEdge{
+				ID:     strconv.Itoa(len(graph.Edges)),
+				Source: strconv.Itoa(n.ID()),
+				Target: strconv.Itoa(d.ID()),
+			}
+			if opt.Weights {
+				xe.Weight = formatValue(e.Wieght())
+			}
+			if opt.EdgeAttrs != nil {
+				if xe.AttValues, err = edgeAttrs.values(opt.EdgeAttrs(e)); err != nil {
+					return false
+				}
+			}
+			graph.Edges = append(graph.Edges, xe)
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, as := range []*attrSet{nodeAttrs, edgeAttrs} {
+		if len(as.list) > 0 {
+			graph.Attributes = append(graph.Attributes, xmlAttributes{Class: as.class, Attrs: as.list})
+		}
+	}
+	doc.Graph = graph
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// attrSet declares attributes of a class while values are written
+type attrSet struct {
+	class string
+	list  []xmlAttr
+	ids   map[string]int // title -> index in list
+}
+
+func newAttrSet(class string) *attrSet {
+	return &attrSet{class: class, ids: make(map[string]int)}
+}
+
+// values converts attributes to attribute values declaring new attributes
+func (as *attrSet) values(attrs Data) ([]xmlAttValue, error) {
+	if len(attrs) == 0 {
+		return nil, nil
+	}
+	titles := make([]string, 0, len(attrs))
+	for title := range attrs {
+		titles = append(titles, title)
+	}
+	sort.Strings(titles)
+
+	res := make([]xmlAttValue, 0, len(attrs))
+	for _, title := range titles {
+		v := attrs[title]
+		typ, err := typeOf(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s attribute %s: %s", as.class, title, err)
+		}
+
+		i, ok := as.ids[title]
+		if !ok {
+			i = len(as.list)
+			as.ids[title] = i
+			as.list = append(as.list, xmlAttr{ID: strconv.Itoa(i), Title: title, Type: typ})
+		} else if as.list[i].Type != typ {
+			return nil, fmt.Errorf("%s attribute %s has values of types %s and %s", as.class, title, as.list[i].Type, typ)
+		}
+		res = append(res, xmlAttValue{For: as.list[i].ID, Value: formatValue(v)})
+	}
+	return res, nil
+}
@@ -0,0 +1,191 @@
+package gml
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/iimos/gorka"
+	"github.com/iimos/gorka/gralang"
+	"github.com/iimos/gorka/internal/graphtest"
+	"github.com/iimos/gorka/types"
+)
+
+func TestParse(t *testing.T) {
+	doc := `# Zachary-like sample
+Creator "test"
+graph
+[
+  directed 0
+  comment "a &quot;small&quot; net"
+  node [ id 1 label "alice" graphics [ x 1.5 y -2E1 ] ]
+  node [ id 2 label "bob" ]
+  node [ id 3 ]
+  edge [ source 1 target 2 value 3 ]
+  edge [ source 2 target 3 weight 0.5 value 7 ]
+]
+`
+	g := gorka.New()
+	d := NewDecoder(strings.NewReader(doc))
+	nodes := map[string]Attrs{}
+	d.OnNode(func(n types.Node, attrs Attrs) {
+		nodes[n.Label()] = attrs
+	})
+	edges := 0
+	d.OnEdge(func(e types.Edge, attrs Attrs) {
+		edges++
+	})
+	if err := d.Decode(g); err != nil {
+		t.Fatalf("decode error: %s", err)
+	}
+
+	if g.NodesCount() != 3 {
+		t.Errorf("wrong nodes count: %d", g.NodesCount())
+	}
+	if g.EdgesCount() != 4 {
+		t.Errorf("wrong edges count: %d", g.EdgesCount())
+	}
+	if edges != 2 {
+		t.Errorf("wrong edge callbacks count: %d", edges)
+	}
+
+	type e struct {
+		a, b string
+		w    float32
+	}
+	for _, c := range []e{{"alice", "bob", 3}, {"bob", "alice", 3}, {"bob", "3", 0.5}, {"3", "bob", 0.5}} {
+		if w, ok := graphtest.Weight(g, c.a, c.b); !ok || w != c.w {
+			t.Errorf("no edge %s->%s of weight %v", c.a, c.b, c.w)
+		}
+	}
+
+	graphics, _ := nodes["alice"]["graphics"].(Attrs)
+	if graphics["x"] != 1.5 || graphics["y"] != -20.0 {
+		t.Errorf("wrong graphics of alice: %v", nodes["alice"]["graphics"])
+	}
+	h := d.Header()
+	if h.Directed || h.Attrs["comment"] != `a "small" net` {
+		t.Errorf("wrong header: %+v", h)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []string{
+		``,
+		`Creator "x"`,
+		`graph 1`,
+		`graph [ node [ label "a" ] ]`,
+		`graph [ node [ id 1 ] node [ id 1 ] ]`,
+		`graph [ node [ id 1 ] edge [ source 1 target 2 ] ]`,
+		`graph [ node [ id 1 ] edge [ target 1 ] ]`,
+		`graph [ node [ id 1 ] edge [ source 1 target 1 weight "x" ] ]`,
+		`graph [ node 1 ]`,
+		`graph [ node [ id 1 ]`,
+		`graph [ node [ id "1 ] ]`,
+		`graph [ node [ id 1- ] ]`,
+		`graph [ [ ] ]`,
+		`graph [ node [ id 1 ] ] }`,
+		`graph [ x ]`,
+		strings.Repeat("a [ ", 100) + strings.Repeat("] ", 100),
+	}
+	for i, c := range cases {
+		if err := Parse(gorka.New(), c); err == nil {
+			t.Errorf("#%d: expected error", i)
+		}
+	}
+	if err := Parse(nil, "graph [ ]"); err == nil {
+		t.Errorf("expected error on nil graph")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	g := gorka.New()
+	gralang.Parse(g, `
+		a -> b
+		b -- c
+	`)
+	g.NewNode("")
+
+	var buf bytes.Buffer
+	err := Write(&buf, g, &Options{
+		Undirected: true,
+		Weights:    true,
+		GraphAttrs: Attrs{"name": `"q"`},
+		NodeAttrs: func(n types.Node) Attrs {
+			if n.Label() == "a" {
+				return Attrs{"graphics": Attrs{"x": 1, "w": float32(2)}}
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `graph [
+  directed 0
+  name "&quot;q&quot;"
+  node [
+    id 1
+    label "a"
+    graphics [
+      w 2.0
+      x 1
+    ]
+  ]
+  node [
+    id 2
+    label "b"
+  ]
+  node [
+    id 3
+    label "c"
+  ]
+  node [
+    id 4
+  ]
+  edge [
+    source 1
+    target 2
+    weight 1.0
+  ]
+  edge [
+    source 2
+    target 3
+    weight 1.0
+  ]
+]
+`
+	if buf.String() != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, buf.String())
+	}
+
+	g2 := gorka.New()
+	d := NewDecoder(&buf)
+	if err := d.Decode(g2); err != nil {
+		t.Fatal(err)
+	}
+	if g2.NodesCount() != 4 || g2.EdgesCount() != 4 {
+		t.Errorf("round trip: expected 4 nodes and 4 edges, got %d and %d", g2.NodesCount(), g2.EdgesCount())
+	}
+	if d.Header().Attrs["name"] != `"q"` {
+		t.Errorf("round trip: wrong graph attrs %v", d.Header().Attrs)
+	}
+}
+
+func TestWriteErrors(t *testing.T) {
+	g := gorka.New()
+	g.NewNode("a")
+	cases := []Attrs{
+		{"bad key": 1},
+		{"1x": 1},
+		{"k": true},
+		{"k": Attrs{"v": []int{1}}},
+	}
+	for i, c := range cases {
+		attrs := c
+		err := Write(&bytes.Buffer{}, g, &Options{NodeAttrs: func(types.Node) Attrs { return attrs }})
+		if err == nil {
+			t.Errorf("#%d: expected error", i)
+		}
+	}
+}
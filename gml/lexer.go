@@ -0,0 +1,142 @@
+package gml
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	tokEOF = iota
+	tokKey
+	tokInt
+	tokReal
+	tokString
+	tokOpen
+	tokClose
+)
+
+type token struct {
+	kind int
+	s    string
+	line int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of input"
+	}
+	return "'" + t.s + "'"
+}
+
+type lexer struct {
+	s    string
+	pos  int
+	line int
+}
+
+func (l *lexer) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", l.line, fmt.Sprintf(format, args...))
+}
+
+// skip skips spaces and comment lines
+func (l *lexer) skip() {
+	for l.pos < len(l.s) {
+		ch := l.s[l.pos]
+		switch {
+		case ch == '\n':
+			l.line++
+			l.pos++
+		case ch == ' ' || ch == '\t' || ch == '\r' || ch == '\f' || ch == '\v':
+			l.pos++
+		case ch == '#':
+			i := strings.IndexByte(l.s[l.pos:], '\n')
+			if i < 0 {
+				i = len(l.s) - l.pos
+			}
+			l.pos += i
+		default:
+			return
+		}
+	}
+}
+
+func (l *lexer) next() (token, error) {
+	l.skip()
+	if l.pos >= len(l.s) {
+		return token{kind: tokEOF, line: l.line}, nil
+	}
+
+	line := l.line
+	rest := l.s[l.pos:]
+	ch := rest[0]
+
+	switch {
+	case ch == '[':
+		l.pos++
+		return token{kind: tokOpen, s: "[", line: line}, nil
+	case ch == ']':
+		l.pos++
+		return token{kind: tokClose, s: "]", line: line}, nil
+	case ch == '"':
+		i := strings.IndexByte(rest[1:], '"')
+		if i < 0 {
+			return token{}, l.errorf("unterminated string")
+		}
+		s := rest[1 : i+1]
+		l.line += strings.Count(s, "\n")
+		l.pos += i + 2
+		return token{kind: tokString, s: s, line: line}, nil
+	case ch == '+' || ch == '-' || ch == '.' || ch >= '0' && ch <= '9':
+		return l.number()
+	case ch == '_' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z':
+		i := 1
+		for i < len(rest) && (rest[i] == '_' || rest[i] >= 'a' && rest[i] <= 'z' || rest[i] >= 'A' && rest[i] <= 'Z' ||
+			rest[i] >= '0' && rest[i] <= '9') {
+			i++
+		}
+		l.pos += i
+		return token{kind: tokKey, s: rest[:i], line: line}, nil
+	}
+	return token{}, l.errorf("unexpected character '%c'", ch)
+}
+
+// number reads an integer or a real: [sign] digits [. digits] [E [sign] digits]
+func (l *lexer) number() (token, error) {
+	rest := l.s[l.pos:]
+	digits := func(i int) int {
+		for i < len(rest) && rest[i] >= '0' && rest[i] <= '9' {
+			i++
+		}
+		return i
+	}
+
+	kind := tokInt
+	i := 0
+	if rest[i] == '+' || rest[i] == '-' {
+		i++
+	}
+	start := i
+	i = digits(i)
+	n := i - start
+	if i < len(rest) && rest[i] == '.' {
+		kind = tokReal
+		j := digits(i + 1)
+		n += j - i - 1
+		i = j
+	}
+	if n == 0 {
+		return token{}, l.errorf("wrong number '%s'", rest[:i])
+	}
+	if i < len(rest) && (rest[i] == 'e' || rest[i] == 'E') {
+		j := i + 1
+		if j < len(rest) && (rest[j] == '+' || rest[j] == '-') {
+			j++
+		}
+		if k := digits(j); k > j {
+			kind = tokReal
+			i = k
+		}
+	}
+	l.pos += i
+	return token{kind: kind, s: rest[:i], line: l.line}, nil
+}
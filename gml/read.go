@@ -0,0 +1,304 @@
+package gml
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/iimos/gorka/internal/graphutil"
+	"github.com/iimos/gorka/types"
+)
+
+// Attrs holds GML key-value pairs. Values are int, float64, string or
+// nested Attrs for lists. When a key repeats the last value is kept.
+type Attrs map[string]interface{}
+
+// Header describes the graph of a GML document
+type Header struct {
+	Directed bool
+	Attrs    Attrs // graph attributes except nodes and edges
+}
+
+// Decoder reads a GML document into a Graph.
+//
+// Nodes are labeled with their `label` attribute, nodes without a label
+// with their id. Edge weight is taken from the `weight` attribute, then from
+// `value`, otherwise it is 1. Graphs are undirected unless `directed 1`
+// is set, undirected edges are added in both directions.
+type Decoder struct {
+	r      io.Reader
+	header Header
+	onNode func(n types.Node, attrs Attrs)
+	onEdge func(e types.Edge, attrs Attrs)
+}
+
+// NewDecoder returns a decoder that reads from r
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r}
+}
+
+// OnNode sets fn to be called for each node with its attributes
+func (d *Decoder) OnNode(fn func(n types.Node, attrs Attrs)) {
+	d.onNode = fn
+}
+
+// OnEdge sets fn to be called for each edge with its attributes.
+// For undirected graphs it gets the edge from source to target.
+func (d *Decoder) OnEdge(fn func(e types.Edge, attrs Attrs)) {
+	d.onEdge = fn
+}
+
+// Header returns the graph description of the decoded document
+func (d *Decoder) Header() Header {
+	return d.header
+}
+
+// Decode reads the document and fills the graph
+func (d *Decoder) Decode(g types.Graph) error {
+	if g == nil {
+		return errors.New("graph is empty")
+	}
+	data, err := io.ReadAll(d.r)
+	if err != nil {
+		return err
+	}
+
+	p := &parser{lex: lexer{s: string(data), line: 1}}
+	var graph []pair
+	found := false
+	if err := p.next(); err != nil {
+		return err
+	}
+	for p.tok.kind != tokEOF {
+		key, v, err := p.pair()
+		if err != nil {
+			return err
+		}
+		if key == "graph" && !found {
+			list, ok := v.([]pair)
+			if !ok {
+				return errors.New("graph is not a list")
+			}
+			graph, found = list, true
+		}
+	}
+	if !found {
+		return errors.New("document has no graph")
+	}
+
+	d.header = Header{Attrs: Attrs{}}
+	var nodes, edges [][]pair
+	for _, kv := range graph {
+		switch kv.key {
+		case "node", "edge":
+			list, ok := kv.value.([]pair)
+			if !ok {
+				return fmt.Errorf("line %d: %s is not a list", kv.line, kv.key)
+			}
+			if kv.key == "node" {
+				nodes = append(nodes, list)
+			} else {
+				edges = append(edges, list)
+			}
+		case "directed":
+			d.header.Directed = kv.value == 1
+		default:
+			d.header.Attrs[kv.key] = toAttr(kv.value)
+		}
+	}
+
+	ns := graphutil.NewNodes(g)
+	for _, list := range nodes {
+		attrs := toAttrs(list)
+		id, ok := idOf(attrs["id"])
+		if !ok {
+			return fmt.Errorf("line %d: node without id", list0(list))
+		}
+		label, _ := attrs["label"].(string)
+		n, err := ns.Add(id, label)
+		if err != nil {
+			return fmt.Errorf("line %d: %s", list0(list), err)
+		}
+		if d.onNode != nil {
+			d.onNode(n, attrs)
+		}
+	}
+
+	for _, list := range edges {
+		attrs := toAttrs(list)
+		line := list0(list)
+		src, ok := lookup(ns, attrs["source"])
+		if !ok {
+			return fmt.Errorf("line %d: wrong edge source %v", line, attrs["source"])
+		}
+		dst, ok := lookup(ns, attrs["target"])
+		if !ok {
+			return fmt.Errorf("line %d: wrong edge target %v", line, attrs["target"])
+		}
+
+		w := float32(1)
+		v, ok := attrs["weight"]
+		if !ok {
+			v, ok = attrs["value"]
+		}
+		if ok {
+			if w, ok = toWeight(v); !ok {
+				return fmt.Errorf("line %d: weight is not a number: %v", line, v)
+			}
+		}
+
+		e := g.AddEdge(src, dst, w)
+		if !d.header.Directed {
+			g.AddEdge(dst, src, w)
+		}
+		if d.onEdge != nil {
+			d.onEdge(e, attrs)
+		}
+	}
+	return nil
+}
+
+// Parse parses GML document and fills the graph
+func Parse(g types.Graph, s string) error {
+	return NewDecoder(strings.NewReader(s)).Decode(g)
+}
+
+// pair is a key-value pair, value is int, float64, string or []pair
+type pair struct {
+	key   string
+	value interface{}
+	line  int
+}
+
+func toAttr(v interface{}) interface{} {
+	if list, ok := v.([]pair); ok {
+		return toAttrs(list)
+	}
+	return v
+}
+
+func toAttrs(list []pair) Attrs {
+	attrs := make(Attrs, len(list))
+	for _, kv := range list {
+		attrs[kv.key] = toAttr(kv.value)
+	}
+	return attrs
+}
+
+// list0 returns the line of the first pair of the list
+func list0(list []pair) int {
+	if len(list) == 0 {
+		return 0
+	}
+	return list[0].line
+}
+
+func idOf(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case int:
+		return strconv.Itoa(v), true
+	case string:
+		return v, v != ""
+	}
+	return "", false
+}
+
+func lookup(ns *graphutil.Nodes, v interface{}) (types.Node, bool) {
+	id, ok := idOf(v)
+	if !ok {
+		return nil, false
+	}
+	return ns.Get(id)
+}
+
+func toWeight(v interface{}) (float32, bool) {
+	switch v := v.(type) {
+	case int:
+		return float32(v), true
+	case float64:
+		return float32(v), true
+	case string:
+		w, err := strconv.ParseFloat(v, 32)
+		return float32(w), err == nil
+	}
+	return 0, false
+}
+
+type parser struct {
+	lex   lexer
+	tok   token
+	depth int
+}
+
+// maxDepth limits nesting of lists
+const maxDepth = 64
+
+func (p *parser) next() error {
+	t, err := p.lex.next()
+	p.tok = t
+	return err
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", p.tok.line, fmt.Sprintf(format, args...))
+}
+
+// pair parses `key value`
+func (p *parser) pair() (string, interface{}, error) {
+	if p.tok.kind != tokKey {
+		return "", nil, p.errorf("expected key, got %s", p.tok)
+	}
+	key := p.tok.s
+	if err := p.next(); err != nil {
+		return "", nil, err
+	}
+
+	var v interface{}
+	switch p.tok.kind {
+	case tokInt:
+		i, err := strconv.Atoi(p.tok.s)
+		if err != nil {
+			return "", nil, p.errorf("wrong integer %s", p.tok.s)
+		}
+		v = i
+	case tokReal:
+		f, err := strconv.ParseFloat(p.tok.s, 64)
+		if err != nil {
+			return "", nil, p.errorf("wrong real %s", p.tok.s)
+		}
+		v = f
+	case tokString:
+		v = html.UnescapeString(p.tok.s)
+	case tokOpen:
+		p.depth++
+		if p.depth > maxDepth {
+			return "", nil, p.errorf("lists are nested too deep")
+		}
+		if err := p.next(); err != nil {
+			return "", nil, err
+		}
+		list := []pair{}
+		for p.tok.kind != tokClose {
+			if p.tok.kind == tokEOF {
+				return "", nil, p.errorf("unclosed list %s", key)
+			}
+			line := p.tok.line
+			k, v, err := p.pair()
+			if err != nil {
+				return "", nil, err
+			}
+			list = append(list, pair{key: k, value: v, line: line})
+		}
+		p.depth--
+		v = list
+	default:
+		return "", nil, p.errorf("expected value of %s, got %s", key, p.tok)
+	}
+	if err := p.next(); err != nil {
+		return "", nil, err
+	}
+	return key, v, nil
+}
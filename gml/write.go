@@ -0,0 +1,166 @@
+package gml
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iimos/gorka/internal/graphutil"
+	"github.com/iimos/gorka/types"
+)
+
+// Options controls how a graph is written
+type Options struct {
+	// Undirected writes `directed 0`, see graphutil.SkipOpposite
+	Undirected bool
+	// Weights writes edge weights as `weight` attribute
+	Weights bool
+
+	// GraphAttrs are written as graph attributes
+	GraphAttrs Attrs
+	// NodeAttrs returns attributes of the node, may be nil
+	NodeAttrs func(n types.Node) Attrs
+	// EdgeAttrs returns attributes of the edge, may be nil
+	EdgeAttrs func(e types.Edge) Attrs
+}
+
+// Write writes the graph as GML document. Node ids are node IDs,
+// labels are written when not empty. Attributes are written sorted by key,
+// values must be integers, floats, strings or nested Attrs.
+func Write(w io.Writer, g types.Graph, opt *Options) error {
+	if opt == nil {
+		opt = &Options{}
+	}
+	b := bufio.NewWriter(w)
+
+	directed := 1
+	if opt.Undirected {
+		directed = 0
+	}
+	b.WriteString("graph [\n")
+	fmt.Fprintf(b, "  directed %d\n", directed)
+	if err := writeAttrs(b, opt.GraphAttrs, 1); err != nil {
+		return err
+	}
+
+	var err error
+	g.NodeIter(func(n types.Node) bool {
+		b.WriteString("  node [\n")
+		fmt.Fprintf(b, "    id %d\n", n.ID())
+		if l := n.Label(); l != "" {
+			b.WriteString("    label ")
+			b.WriteString(quote(l))
+			b.WriteByte('\n')
+		}
+		if opt.NodeAttrs != nil {
+			if err = writeAttrs(b, opt.NodeAttrs(n), 2); err != nil {
+				return false
+			}
+		}
+		b.WriteString("  ]\n")
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	g.NodeIter(func(n types.Node) bool {
+		for _, e := range graphutil.SortedEdges(g, n) {
+			d := e.Dst()
+			if opt.Undirected && graphutil.SkipOpposite(g, n, d) {
+				continue
+			}
+			b.WriteString("  edge [\n")
+			fmt.Fprintf(b, "    source %d\n    target %d\n", n.ID(), d.ID())
+			if opt.Weights {
+				b.WriteString("    weight ")
+				b.WriteString(formatFloat(float64(e.Wieght()), 32))
+				b.WriteByte('\n')
+			}
+			if opt.EdgeAttrs != nil {
+				if err = writeAttrs(b, opt.EdgeAttrs(e), 2); err != nil {
+					return false
+				}
+			}
+			b.WriteString("  ]\n")
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	b.WriteString("]\n")
+	return b.Flush()
+}
+
+func writeAttrs(b *bufio.Writer, attrs Attrs, depth int) error {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		if !isKey(k) {
+			return fmt.Errorf("wrong key '%s'", k)
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	indent := strings.Repeat("  ", depth)
+	for _, k := range keys {
+		b.WriteString(indent)
+		b.WriteString(k)
+		b.WriteByte(' ')
+		switch v := attrs[k].(type) {
+		case int:
+			b.WriteString(strconv.Itoa(v))
+		case int64:
+			b.WriteString(strconv.FormatInt(v, 10))
+		case float32:
+			b.WriteString(formatFloat(float64(v), 32))
+		case float64:
+			b.WriteString(formatFloat(v, 64))
+		case string:
+			b.WriteString(quote(v))
+		case Attrs:
+			b.WriteString("[\n")
+			if err := writeAttrs(b, v, depth+1); err != nil {
+				return err
+			}
+			b.WriteString(indent)
+			b.WriteByte(']')
+		default:
+			return fmt.Errorf("%s: unsupported value type %T", k, v)
+		}
+		b.WriteByte('\n')
+	}
+	return nil
+}
+
+// formatFloat formats a float so it is read back as a real
+func formatFloat(f float64, bitSize int) string {
+	s := strconv.FormatFloat(f, 'g', -1, bitSize)
+	if !strings.ContainsAny(s, ".eEnN") {
+		s += ".0"
+	}
+	return s
+}
+
+var quoter = strings.NewReplacer(`&`, "&amp;", `"`, "&quot;")
+
+func quote(s string) string {
+	return `"` + quoter.Replace(s) + `"`
+}
+
+func isKey(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 0 && r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
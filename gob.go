@@ -0,0 +1,18 @@
+package gorka
+
+import "encoding/gob"
+
+func init() {
+	// graphs stored in Graph fields of gob encoded structs
+	gob.Register(newGraph())
+}
+
+// GobEncode implements gob.GobEncoder, the graph is encoded in binary format
+func (g *graph) GobEncode() ([]byte, error) {
+	return g.MarshalBinary()
+}
+
+// GobDecode implements gob.GobDecoder. It replaces the graph content.
+func (g *graph) GobDecode(data []byte) error {
+	return g.UnmarshalBinary(data)
+}
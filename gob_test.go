@@ -0,0 +1,47 @@
+package gorka
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+
+	"github.com/iimos/gorka/gralang"
+)
+
+func TestGob(t *testing.T) {
+	g := New()
+	gralang.Parse(g, "a -> b c; c -- d")
+	a, _ := g.NodeByLabel("a")
+	n, _ := g.NewNode("")
+	g.AddEdge(a, n, 0.5)
+
+	type stage struct {
+		Name  string
+		Graph Graph
+	}
+	var b bytes.Buffer
+	if err := gob.NewEncoder(&b).Encode(stage{"first", g}); err != nil {
+		t.Fatalf("encode error: %s", err)
+	}
+	var res stage
+	if err := gob.NewDecoder(&b).Decode(&res); err != nil {
+		t.Fatalf("decode error: %s", err)
+	}
+	if res.Name != "first" || res.Graph == nil || !sameGraph(g, res.Graph) {
+		t.Errorf("wrong decoded value %q:\n%s\nexpected:\n%s", res.Name, res.Graph, g)
+	}
+
+	// graph as a top level value
+	b.Reset()
+	if err := gob.NewEncoder(&b).Encode(g); err != nil {
+		t.Fatalf("encode error: %s", err)
+	}
+	g2 := New()
+	gralang.Parse(g2, "old -> content")
+	if err := gob.NewDecoder(&b).Decode(g2); err != nil {
+		t.Fatalf("decode error: %s", err)
+	}
+	if !sameGraph(g, g2) {
+		t.Errorf("graph changed:\n%s\nexpected:\n%s", g2, g)
+	}
+}
@@ -0,0 +1,216 @@
+package godeps
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iimos/gorka/internal/graphutil"
+	"github.com/iimos/gorka/types"
+)
+
+// ImportOptions controls building of import graphs
+type ImportOptions struct {
+	// Module is the import path of the source tree root,
+	// empty means the module path from go.mod in the root
+	Module string
+	// Tests adds imports of _test.go files to their packages, external
+	// tests (package foo_test) become a node of their own labeled as
+	// the package import path with _test suffix
+	Tests bool
+	// Std adds imports of standard library packages
+	Std bool
+	// External adds imports of packages outside of the module,
+	// otherwise only packages of the module are in the graph
+	External bool
+}
+
+// ImportGraph builds the import graph of Go packages in the directory,
+// see ImportGraphFS
+func ImportGraph(g types.Graph, dir string, opt *ImportOptions) error {
+	return ImportGraphFS(g, os.DirFS(dir), opt)
+}
+
+// ImportGraphFS builds the import graph of Go packages in the file system.
+// Packages become nodes labeled with import paths, imports become edges
+// of weight 1 from the importing package. Directories named vendor or
+// testdata, starting with '.' or '_' and nested modules are skipped.
+// Build constraints are not evaluated, all files of a package are used.
+func ImportGraphFS(g types.Graph, fsys fs.FS, opt *ImportOptions) error {
+	if g == nil {
+		return errors.New("graph is empty")
+	}
+	if opt == nil {
+		opt = &ImportOptions{}
+	}
+	module := opt.Module
+	if module == "" {
+		var err error
+		if module, err = modulePath(fsys, "go.mod"); err != nil {
+			return err
+		}
+	}
+
+	// imports by package import path
+	pkgs := make(map[string]map[string]bool)
+	fset := token.NewFileSet()
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if name == "." {
+				return nil
+			}
+			base := d.Name()
+			if base == "vendor" || base == "testdata" || strings.HasPrefix(base, ".") || strings.HasPrefix(base, "_") {
+				return fs.SkipDir
+			}
+			if _, err := fs.Stat(fsys, path.Join(name, "go.mod")); err == nil {
+				// nested module
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(name, ".go") || strings.HasPrefix(d.Name(), ".") || strings.HasPrefix(d.Name(), "_") {
+			return nil
+		}
+		if !opt.Tests && strings.HasSuffix(name, "_test.go") {
+			return nil
+		}
+
+		src, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		f, err := parser.ParseFile(fset, name, src, parser.ImportsOnly)
+		if err != nil {
+			return err
+		}
+
+		pkg := module
+		if dir := path.Dir(name); dir != "." {
+			pkg = module + "/" + dir
+		}
+		if strings.HasSuffix(f.Name.Name, "_test") && strings.HasSuffix(name, "_test.go") {
+			// external tests are not part of the package, crediting their
+			// imports to it would make cycles which don't exist
+			pkg += "_test"
+		}
+		imports, ok := pkgs[pkg]
+		if !ok {
+			imports = make(map[string]bool)
+			pkgs[pkg] = imports
+		}
+		for _, spec := range f.Imports {
+			p, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				return fmt.Errorf("%s: wrong import %s", name, spec.Path.Value)
+			}
+			if p == "C" {
+				// cgo
+				continue
+			}
+			imports[p] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	inModule := func(p string) bool {
+		return p == module || strings.HasPrefix(p, module+"/")
+	}
+
+	// sorted for stable node order
+	names := make([]string, 0, len(pkgs))
+	for p := range pkgs {
+		names = append(names, p)
+	}
+	sort.Strings(names)
+	for _, p := range names {
+		graphutil.Obtain(g, p)
+	}
+	for _, p := range names {
+		src := graphutil.Obtain(g, p)
+		imports := make([]string, 0, len(pkgs[p]))
+		for imp := range pkgs[p] {
+			imports = append(imports, imp)
+		}
+		sort.Strings(imports)
+		for _, imp := range imports {
+			switch {
+			case inModule(imp):
+			case IsStd(imp):
+				if !opt.Std {
+					continue
+				}
+			default:
+				if !opt.External {
+					continue
+				}
+			}
+			g.AddEdge(src, graphutil.Obtain(g, imp), 1)
+		}
+	}
+	return nil
+}
+
+// IsStd reports whether the import path belongs to the standard library,
+// which is when its first element has no dot
+func IsStd(importPath string) bool {
+	first := importPath
+	if i := strings.IndexByte(importPath, '/'); i >= 0 {
+		first = importPath[:i]
+	}
+	return !strings.Contains(first, ".")
+}
+
+// modulePath reads the module path from go.mod file
+func modulePath(fsys fs.FS, name string) (string, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", errors.New("go.mod is missing, set ImportOptions.Module")
+		}
+		return "", err
+	}
+	defer f.Close()
+
+	scan := bufio.NewScanner(f)
+	for scan.Scan() {
+		line := strings.TrimSpace(scan.Text())
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		rest := strings.TrimPrefix(line, "module")
+		if rest == line || rest == "" || rest[0] != ' ' && rest[0] != '\t' && rest[0] != '"' {
+			continue
+		}
+		rest = strings.TrimSpace(rest)
+		if strings.HasPrefix(rest, `"`) || strings.HasPrefix(rest, "`") {
+			p, err := strconv.Unquote(rest)
+			if err != nil {
+				return "", fmt.Errorf("%s: wrong module path %s", name, rest)
+			}
+			rest = p
+		}
+		if rest == "" {
+			break
+		}
+		return rest, nil
+	}
+	if err := scan.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("%s: module path is missing", name)
+}
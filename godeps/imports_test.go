@@ -0,0 +1,168 @@
+package godeps
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/iimos/gorka"
+	"github.com/iimos/gorka/types"
+)
+
+var tree = fstest.MapFS{
+	"go.mod": {Data: []byte("// app\nmodule example.com/app // comment\n\ngo 1.21\n")},
+	"main.go": {Data: []byte(`package main
+
+import (
+	"fmt"
+
+	"example.com/app/db"
+	"example.com/app/web"
+)
+
+func main() { fmt.Println(db.X, web.Y) }
+`)},
+	"db/db.go": {Data: []byte(`package db
+
+import "C"
+import "github.com/lib/pq"
+
+var X = pq.Y
+`)},
+	"db/db_test.go": {Data: []byte(`package db_test
+
+import (
+	"testing"
+
+	"example.com/app/db"
+	"example.com/app/testutil"
+)
+`)},
+	"web/web.go": {Data: []byte(`package web
+
+import (
+	"net/http"
+	_ "example.com/app/db"
+)
+`)},
+	"testutil/util.go":    {Data: []byte("package testutil\n")},
+	"vendor/x/x.go":       {Data: []byte("package x\n")},
+	"testdata/bad.go":     {Data: []byte("not go")},
+	".git/hooks.go":       {Data: []byte("not go")},
+	"_old/old.go":         {Data: []byte("not go")},
+	"tools/go.mod":        {Data: []byte("module example.com/app/tools\n")},
+	"tools/tools.go":      {Data: []byte("package tools\n")},
+	"web/static/style.go": {Data: []byte("package static\n")},
+	"web/README.md":       {Data: []byte("# web\n")},
+}
+
+func hasEdge(g types.Graph, a, b string) bool {
+	na, ok1 := g.NodeByLabel(a)
+	nb, ok2 := g.NodeByLabel(b)
+	return ok1 && ok2 && g.HasEdgeBetween(na, nb)
+}
+
+func TestImportGraph(t *testing.T) {
+	type tcase struct {
+		opt       *ImportOptions
+		nodeCount int
+		edgeCount int
+		edges     [][2]string
+		missing   [][2]string
+	}
+	cases := []tcase{
+		tcase{nil, 5, 3,
+			[][2]string{{"example.com/app", "example.com/app/db"}, {"example.com/app", "example.com/app/web"},
+				{"example.com/app/web", "example.com/app/db"}},
+			[][2]string{{"example.com/app/db", "example.com/app/testutil"}}},
+		tcase{&ImportOptions{Tests: true}, 6, 5,
+			[][2]string{{"example.com/app/db_test", "example.com/app/db"}, {"example.com/app/db_test", "example.com/app/testutil"}},
+			[][2]string{{"example.com/app/db", "example.com/app/testutil"}}},
+		tcase{&ImportOptions{Std: true, External: true}, 8, 6,
+			[][2]string{{"example.com/app", "fmt"}, {"example.com/app/web", "net/http"},
+				{"example.com/app/db", "github.com/lib/pq"}}, nil},
+		tcase{&ImportOptions{Module: "other"}, 5, 0, nil, nil},
+	}
+	for i, c := range cases {
+		g := gorka.New()
+		if err := ImportGraphFS(g, tree, c.opt); err != nil {
+			t.Errorf("#%d: %s", i, err)
+			continue
+		}
+		if g.NodesCount() != c.nodeCount {
+			t.Errorf("#%d: expected %d nodes, got %d: %s", i, c.nodeCount, g.NodesCount(), g)
+		}
+		if g.EdgesCount() != c.edgeCount {
+			t.Errorf("#%d: expected %d edges, got %d: %s", i, c.edgeCount, g.EdgesCount(), g)
+		}
+		for _, e := range c.edges {
+			if !hasEdge(g, e[0], e[1]) {
+				t.Errorf("#%d: no edge %s -> %s", i, e[0], e[1])
+			}
+		}
+		for _, e := range c.missing {
+			if hasEdge(g, e[0], e[1]) {
+				t.Errorf("#%d: unexpected edge %s -> %s", i, e[0], e[1])
+			}
+		}
+		if _, ok := g.NodeByLabel("example.com/app/tools"); ok {
+			t.Errorf("#%d: nested module is added", i)
+		}
+	}
+}
+
+func TestImportGraphErrors(t *testing.T) {
+	cases := []fstest.MapFS{
+		{"main.go": {Data: []byte("package main\n")}},
+		{"go.mod": {Data: []byte("go 1.21\n")}},
+		{"go.mod": {Data: []byte("module a\n")}, "a.go": {Data: []byte("not go\n")}},
+	}
+	for i, c := range cases {
+		if err := ImportGraphFS(gorka.New(), c, nil); err == nil {
+			t.Errorf("#%d: expected error", i)
+		}
+	}
+	if err := ImportGraphFS(nil, tree, nil); err == nil {
+		t.Errorf("expected error on nil graph")
+	}
+}
+
+func TestImportGraphDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":   "module \"example.com/q\"\n",
+		"q.go":     "package q\nimport _ \"example.com/q/sub\"\n",
+		"sub/s.go": "package sub\n",
+	}
+	for name, data := range files {
+		p := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(p), 0o755)
+		if err := os.WriteFile(p, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	g := gorka.New()
+	if err := ImportGraph(g, dir, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !hasEdge(g, "example.com/q", "example.com/q/sub") {
+		t.Errorf("no edge example.com/q -> example.com/q/sub: %s", g)
+	}
+}
+
+func TestIsStd(t *testing.T) {
+	cases := map[string]bool{
+		"fmt":                 true,
+		"net/http":            true,
+		"github.com/lib/pq":   false,
+		"example.com":         false,
+		"golang.org/x/net/ip": false,
+	}
+	for p, want := range cases {
+		if IsStd(p) != want {
+			t.Errorf("IsStd(%s) = %v", p, !want)
+		}
+	}
+}
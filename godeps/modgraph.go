@@ -0,0 +1,66 @@
+package godeps
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/iimos/gorka/internal/graphutil"
+	"github.com/iimos/gorka/types"
+)
+
+// ModOptions controls reading of `go mod graph` output
+type ModOptions struct {
+	// IgnoreVersions labels nodes with module paths only,
+	// so all versions of a module become one node
+	IgnoreVersions bool
+}
+
+// ReadModGraph reads `go mod graph` output: a pair of modules per line,
+// the main module without version and requirements as module@version.
+// Modules become nodes labeled as written and requirements become edges
+// of weight 1 from the requiring module.
+func ReadModGraph(g types.Graph, r io.Reader, opt *ModOptions) error {
+	if g == nil {
+		return errors.New("graph is empty")
+	}
+	if opt == nil {
+		opt = &ModOptions{}
+	}
+
+	node := func(label string) types.Node {
+		if opt.IgnoreVersions {
+			label, _ = SplitVersion(label)
+		}
+		return graphutil.Obtain(g, label)
+	}
+
+	scan := bufio.NewScanner(r)
+	line := 0
+	for scan.Scan() {
+		line++
+		fields := strings.Fields(scan.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return fmt.Errorf("line %d: expected 'module requirement', got %d fields", line, len(fields))
+		}
+		src, dst := node(fields[0]), node(fields[1])
+		if src.ID() != dst.ID() && !g.HasEdgeBetween(src, dst) {
+			g.AddEdge(src, dst, 1)
+		}
+	}
+	return scan.Err()
+}
+
+// SplitVersion splits "module@version" into module path and version,
+// version is empty when there is none
+func SplitVersion(s string) (path, version string) {
+	if i := strings.LastIndexByte(s, '@'); i >= 0 {
+		return s[:i], s[i+1:]
+	}
+	return s, ""
+}
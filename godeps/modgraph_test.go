@@ -0,0 +1,77 @@
+package godeps
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/iimos/gorka"
+)
+
+const modGraph = `example.com/app github.com/pkg/errors@v0.9.1
+example.com/app golang.org/x/text@v0.3.0
+golang.org/x/text@v0.3.0 golang.org/x/tools@v0.1.0
+golang.org/x/tools@v0.1.0 golang.org/x/text@v0.3.7
+
+golang.org/x/text@v0.3.7 golang.org/x/tools@v0.1.0
+`
+
+func TestReadModGraph(t *testing.T) {
+	type tcase struct {
+		opt       *ModOptions
+		nodeCount int
+		edgeCount int
+		edges     [][2]string
+	}
+	cases := []tcase{
+		tcase{nil, 5, 5, [][2]string{
+			{"example.com/app", "github.com/pkg/errors@v0.9.1"},
+			{"golang.org/x/tools@v0.1.0", "golang.org/x/text@v0.3.7"},
+		}},
+		tcase{&ModOptions{IgnoreVersions: true}, 4, 4, [][2]string{
+			{"example.com/app", "golang.org/x/text"},
+			{"golang.org/x/text", "golang.org/x/tools"},
+			{"golang.org/x/tools", "golang.org/x/text"},
+		}},
+	}
+	for i, c := range cases {
+		g := gorka.New()
+		if err := ReadModGraph(g, strings.NewReader(modGraph), c.opt); err != nil {
+			t.Errorf("#%d: %s", i, err)
+			continue
+		}
+		if g.NodesCount() != c.nodeCount {
+			t.Errorf("#%d: expected %d nodes, got %d", i, c.nodeCount, g.NodesCount())
+		}
+		if g.EdgesCount() != c.edgeCount {
+			t.Errorf("#%d: expected %d edges, got %d", i, c.edgeCount, g.EdgesCount())
+		}
+		for _, e := range c.edges {
+			a, _ := g.NodeByLabel(e[0])
+			b, _ := g.NodeByLabel(e[1])
+			if a == nil || b == nil || !g.HasEdgeBetween(a, b) {
+				t.Errorf("#%d: no edge %s -> %s", i, e[0], e[1])
+			}
+		}
+	}
+
+	if err := ReadModGraph(gorka.New(), strings.NewReader("a b c\n"), nil); err == nil {
+		t.Errorf("expected error on a line with 3 fields")
+	}
+	if err := ReadModGraph(nil, strings.NewReader(modGraph), nil); err == nil {
+		t.Errorf("expected error on nil graph")
+	}
+}
+
+func TestSplitVersion(t *testing.T) {
+	cases := [][3]string{
+		{"example.com/app", "example.com/app", ""},
+		{"golang.org/x/text@v0.3.0", "golang.org/x/text", "v0.3.0"},
+		{"go@1.21", "go", "1.21"},
+	}
+	for i, c := range cases {
+		p, v := SplitVersion(c[0])
+		if p != c[1] || v != c[2] {
+			t.Errorf("#%d: expected %s %s, got %s %s", i, c[1], c[2], p, v)
+		}
+	}
+}
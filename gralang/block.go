@@ -0,0 +1,405 @@
+package gralang
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/iimos/gorka/types"
+)
+
+// Block statements of Gralang:
+//
+//	@rack = a b c              named group of nodes, @rack can be used anywhere a node can
+//	include racks.gra          reads another file, relative to the including one
+//	template rack(up) {        template with parameters, the body lasts until a }
+//		tor -> s1 s2; tor -> $up
+//	}
+//	template pair { a -- b }   template with a one line body
+//	r1 = rack(core)            instance: adds r1.tor -> r1.s1 r1.s2, r1.tor -> core
+//	                           and defines group @r1 of all its nodes
+//
+// Labels inside a template body are prefixed with the instance name and a dot,
+// $param is replaced with the argument as is. Outside of templates $ has no
+// special meaning.
+//
+// A statement is taken as a block statement only when it has exactly the form
+// above, otherwise it is a list of nodes and edges as before, so `include`,
+// `template` or `}` alone are node labels, and so is `x = y` when y is not
+// a defined template. @name which is not a defined group is a label too.
+// A backslash at the start of a label makes it literal: \@home is the node
+// "@home" and `\include a` adds nodes "include" and "a".
+//
+// Include reads files only when the caller allows it: with ParseFile or
+// with a Decoder given a file system by SetIncludeFS.
+
+const maxNestingDepth = 32
+
+type template struct {
+	name   string
+	params []string
+	body   []string
+}
+
+// scope is a template instance being expanded
+type scope struct {
+	parent *scope
+	prefix string
+	args   map[string][]string
+	nodes  []string
+	seen   map[string]bool
+	depth  int
+}
+
+// record remembers label as a member of the instance and all enclosing ones
+func (sc *scope) record(label string) {
+	for ; sc != nil; sc = sc.parent {
+		if !sc.seen[label] {
+			sc.seen[label] = true
+			sc.nodes = append(sc.nodes, label)
+		}
+	}
+}
+
+type parser struct {
+	g         types.Graph
+	groups    map[string][]string
+	templates map[string]*template
+	tmpl      *template // template which body is being read
+	scope     *scope
+
+	fsys      fs.FS
+	osFiles   bool // include reads the OS file system, set by ParseFile
+	dir       string
+	including map[string]bool
+}
+
+func newParser(g types.Graph) *parser {
+	return &parser{
+		g:         g,
+		groups:    make(map[string][]string),
+		templates: make(map[string]*template),
+		including: make(map[string]bool),
+	}
+}
+
+// finish checks that the input is not cut in the middle of a block
+func (p *parser) finish() error {
+	if p.tmpl != nil {
+		return fmt.Errorf("template %s is not closed", p.tmpl.name)
+	}
+	return nil
+}
+
+func (p *parser) statement(stmt string) error {
+	fields := strings.Fields(stmt)
+
+	if p.tmpl != nil {
+		if isTemplate(fields) {
+			return errors.New("nested template definitions are not supported")
+		}
+		// the body ends with a } alone or closing its last statement
+		if n := len(fields); n > 0 && fields[n-1] == "}" {
+			if n > 1 {
+				p.tmpl.body = append(p.tmpl.body, strings.Join(fields[:n-1], " "))
+			}
+			t := p.tmpl
+			p.tmpl = nil
+			if _, exists := p.templates[t.name]; exists {
+				return fmt.Errorf("template %s already defined", t.name)
+			}
+			p.templates[t.name] = t
+			return nil
+		}
+		p.tmpl.body = append(p.tmpl.body, stmt)
+		return nil
+	}
+
+	switch {
+	case len(fields) == 0:
+		return nil
+	case len(fields) == 2 && fields[0] == "include" && !hasEdge(stmt):
+		return p.include(fields[1])
+	case isTemplate(fields):
+		return p.beginTemplate(fields[1:])
+	case len(fields) > 2 && fields[1] == "=" && !hasEdge(stmt):
+		if len(fields[0]) > 1 && fields[0][0] == '@' {
+			return p.defineGroup(fields[0][1:], fields[2:])
+		}
+		if t, args, ok := p.templateCall(fields[0], strings.Join(fields[2:], " ")); ok {
+			return p.instantiate(fields[0], t, args)
+		}
+	}
+	return p.edges(stmt)
+}
+
+// hasEdge reports whether s has edge characters
+func hasEdge(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if lext[s[i]] == lexEdge {
+			return true
+		}
+	}
+	return false
+}
+
+// isTemplate reports whether the statement starts a template definition
+func isTemplate(fields []string) bool {
+	if len(fields) == 0 || fields[0] != "template" {
+		return false
+	}
+	for i, f := range fields {
+		if f == "{" {
+			return !hasEdge(strings.Join(fields[:i], " "))
+		}
+	}
+	return false
+}
+
+// defineGroup handles `@name = a b @other`
+func (p *parser) defineGroup(name string, members []string) error {
+	if p.scope != nil {
+		name = p.scope.prefix + name
+	}
+	if _, exists := p.groups[name]; exists {
+		return fmt.Errorf("group @%s already defined", name)
+	}
+
+	labels, err := p.resolveList(members)
+	if err != nil {
+		return err
+	}
+	if len(labels) == 0 {
+		return fmt.Errorf("group @%s is empty", name)
+	}
+	for _, l := range labels {
+		obtainNode(p.g, l)
+	}
+	p.groups[name] = labels
+	return nil
+}
+
+// beginTemplate handles `template name(a b) {`
+func (p *parser) beginTemplate(fields []string) error {
+	brace := -1
+	for i, f := range fields {
+		if f == "{" {
+			brace = i
+			break
+		}
+	}
+	if brace < 0 {
+		return errors.New("template definition should end with {")
+	}
+	name, params, err := readCall(strings.Join(fields[:brace], " "))
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]bool, len(params))
+	for _, prm := range params {
+		if seen[prm] {
+			return fmt.Errorf("template %s: duplicate parameter %s", name, prm)
+		}
+		seen[prm] = true
+	}
+	p.tmpl = &template{name: name, params: params}
+
+	// body starting on the same line: `template name { a -> b }`
+	if rest := fields[brace+1:]; len(rest) > 0 {
+		return p.statement(strings.Join(rest, " "))
+	}
+	return nil
+}
+
+// templateCall reads `inst = name(arg1 arg2)`, ok is false when it is not
+// an instance of a defined template
+func (p *parser) templateCall(inst, call string) (t *template, args []string, ok bool) {
+	if strings.ContainsAny(inst[:1], "@$\\") || readSeq(inst, lexNode) != inst {
+		return nil, nil, false
+	}
+	name, args, err := readCall(call)
+	if err != nil {
+		return nil, nil, false
+	}
+	t, ok = p.templates[name]
+	return t, args, ok
+}
+
+// instantiate expands template t as instance inst
+func (p *parser) instantiate(inst string, t *template, args []string) error {
+	name := t.name
+	if len(args) != len(t.params) {
+		return fmt.Errorf("template %s expects %d arguments, got %d", name, len(t.params), len(args))
+	}
+
+	sc := &scope{
+		parent: p.scope,
+		prefix: inst + ".",
+		args:   make(map[string][]string, len(args)),
+		seen:   make(map[string]bool),
+	}
+	if p.scope != nil {
+		sc.prefix = p.scope.prefix + sc.prefix
+		sc.depth = p.scope.depth + 1
+	}
+	if sc.depth >= maxNestingDepth {
+		return fmt.Errorf("template %s: instances are nested too deep", name)
+	}
+	group := strings.TrimSuffix(sc.prefix, ".")
+	if _, exists := p.groups[group]; exists {
+		return fmt.Errorf("group @%s already defined", group)
+	}
+
+	for i, a := range args {
+		labels, err := p.resolve(a)
+		if err != nil {
+			return err
+		}
+		sc.args[t.params[i]] = labels
+	}
+
+	p.scope = sc
+	defer func() { p.scope = sc.parent }()
+
+	for _, stmt := range t.body {
+		if err := p.statement(stmt); err != nil {
+			return fmt.Errorf("%s = %s: %s", inst, name, err)
+		}
+	}
+
+	if len(sc.nodes) > 0 {
+		p.groups[group] = sc.nodes
+	}
+	return nil
+}
+
+// include handles `include file`
+func (p *parser) include(name string) error {
+	if p.fsys == nil && !p.osFiles {
+		return fmt.Errorf("include %s: includes are not allowed here", name)
+	}
+	var full string
+	if p.fsys != nil {
+		full = path.Join(p.dir, name)
+	} else if filepath.IsAbs(name) {
+		full = name
+	} else {
+		full = filepath.Join(p.dir, name)
+	}
+
+	if p.including[full] {
+		return fmt.Errorf("include %s: cyclic include", name)
+	}
+	if len(p.including) >= maxNestingDepth {
+		return fmt.Errorf("include %s: includes are nested too deep", name)
+	}
+
+	var (
+		f   fs.File
+		err error
+	)
+	if p.fsys != nil {
+		f, err = p.fsys.Open(full)
+	} else {
+		f, err = os.Open(full)
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	p.including[full] = true
+	dir := p.dir
+	if p.fsys != nil {
+		p.dir = path.Dir(full)
+	} else {
+		p.dir = filepath.Dir(full)
+	}
+	defer func() {
+		delete(p.including, full)
+		p.dir = dir
+	}()
+
+	d := NewDecoder(f)
+	d.p = p
+	err = d.decode()
+	if err == nil {
+		err = p.finish()
+	}
+	if err != nil {
+		return fmt.Errorf("include %s: %s", name, err)
+	}
+	return nil
+}
+
+// resolveList expands every label of the list
+func (p *parser) resolveList(labels []string) ([]string, error) {
+	res := make([]string, 0, len(labels))
+	for _, l := range labels {
+		r, err := p.resolve(l)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, r...)
+	}
+	return res, nil
+}
+
+// resolve turns a label as written in the text into graph node labels:
+// defined groups are expanded, template parameters are substituted and
+// plain labels inside a template get the instance prefix.
+func (p *parser) resolve(label string) ([]string, error) {
+	switch label[0] {
+	case '@':
+		name := label[1:]
+		for sc := p.scope; sc != nil; sc = sc.parent {
+			if g, ok := p.groups[sc.prefix+name]; ok {
+				return g, nil
+			}
+		}
+		if g, ok := p.groups[name]; ok {
+			return g, nil
+		}
+	case '$':
+		if p.scope != nil {
+			if a, ok := p.scope.args[label[1:]]; ok {
+				return a, nil
+			}
+			return nil, fmt.Errorf("unknown template parameter %s", label)
+		}
+	case '\\':
+		if len(label) > 1 {
+			label = label[1:]
+		}
+	}
+
+	if p.scope == nil {
+		return []string{label}, nil
+	}
+	l := p.scope.prefix + label
+	p.scope.record(l)
+	return []string{l}, nil
+}
+
+// readCall parses `name(a b c)`; parentheses are optional when there are no arguments
+func readCall(s string) (name string, args []string, err error) {
+	s = strings.TrimSpace(s)
+	open := strings.IndexByte(s, '(')
+	if open < 0 {
+		name = s
+	} else {
+		if !strings.HasSuffix(s, ")") {
+			return "", nil, fmt.Errorf("missing ) in '%s'", s)
+		}
+		name = strings.TrimSpace(s[:open])
+		args = strings.Fields(s[open+1 : len(s)-1])
+	}
+	if name == "" || strings.ContainsAny(name, " ()") {
+		return "", nil, fmt.Errorf("wrong template name in '%s'", s)
+	}
+	return name, args, nil
+}
@@ -0,0 +1,259 @@
+package gralang_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/iimos/gorka"
+	"github.com/iimos/gorka/gralang"
+)
+
+func TestBlocks(t *testing.T) {
+	type e struct{ a, b string }
+	type testcase struct {
+		text      string
+		nodeCount int
+		edgeCount int
+		edges     []e
+	}
+
+	cases := []testcase{
+		testcase{"@g = a b c", 3, 0, []e{}},
+		testcase{"@g = a b; x -> @g", 3, 2, []e{
+			e{"x", "a"}, e{"x", "b"},
+		}},
+		testcase{"@g = a b; @h = @g c; @h -> x", 4, 3, []e{
+			e{"a", "x"}, e{"b", "x"}, e{"c", "x"},
+		}},
+		testcase{`
+			template rack(up) {
+				tor -> s1 s2
+				tor -> $up
+			}
+			r1 = rack(core)
+			r2 = rack(core)
+		`, 7, 6, []e{
+			e{"r1.tor", "r1.s1"}, e{"r1.tor", "r1.s2"}, e{"r1.tor", "core"},
+			e{"r2.tor", "r2.s1"}, e{"r2.tor", "r2.s2"}, e{"r2.tor", "core"},
+		}},
+		testcase{`
+			template pair { a -- b }
+			p = pair
+			x -> @p
+		`, 3, 4, []e{
+			e{"p.a", "p.b"}, e{"p.b", "p.a"}, e{"x", "p.a"}, e{"x", "p.b"},
+		}},
+		testcase{"template pair { a -- b; b -> c }; p = pair", 3, 3, []e{
+			e{"p.a", "p.b"}, e{"p.b", "p.a"}, e{"p.b", "p.c"},
+		}},
+		testcase{"template pair() {; a -> b; }; p = pair(); q = pair(); @p -> @q", 4, 6, []e{
+			e{"p.a", "p.b"}, e{"q.a", "q.b"},
+			e{"p.a", "q.a"}, e{"p.a", "q.b"}, e{"p.b", "q.a"}, e{"p.b", "q.b"},
+		}},
+		testcase{`
+			@spines = s1 s2
+			template rack(up) {
+				@servers = h1 h2
+				tor -> @servers
+				tor -> $up
+			}
+			template pod {
+				a = rack(@spines)
+				b = rack(@spines)
+			}
+			p1 = pod()
+		`, 8, 8, []e{
+			e{"p1.a.tor", "p1.a.h1"}, e{"p1.a.tor", "p1.a.h2"},
+			e{"p1.a.tor", "s1"}, e{"p1.a.tor", "s2"},
+			e{"p1.b.tor", "p1.b.h1"}, e{"p1.b.tor", "s2"},
+		}},
+	}
+
+	for i, c := range cases {
+		g := gorka.New()
+		err := gralang.Parse(g, c.text)
+		if err != nil {
+			t.Errorf("#%d: parse error: %s", i, err)
+			continue
+		}
+
+		if g.NodesCount() != c.nodeCount {
+			t.Errorf("#%d: wrong nodes count - %d, expected %d", i, g.NodesCount(), c.nodeCount)
+		}
+		if g.EdgesCount() != c.edgeCount {
+			t.Errorf("#%d: wrong edges count - %d, expected %d", i, g.EdgesCount(), c.edgeCount)
+		}
+
+		for _, e := range c.edges {
+			a, ok := g.NodeByLabel(e.a)
+			if !ok {
+				t.Errorf("#%d: no node with label %s", i, e.a)
+				continue
+			}
+			b, ok := g.NodeByLabel(e.b)
+			if !ok {
+				t.Errorf("#%d: no node with label %s", i, e.b)
+				continue
+			}
+			if !g.HasEdgeBetween(a, b) {
+				t.Errorf("#%d: no edge %s->%s", i, e.a, e.b)
+			}
+		}
+	}
+}
+
+func TestBlocksInstanceGroup(t *testing.T) {
+	g := gorka.New()
+	err := gralang.Parse(g, `
+		template leaf { x }
+		template pod {
+			a = leaf
+			b = leaf
+		}
+		p = pod
+		root -> @p
+		other -> @p.a
+	`)
+	if err != nil {
+		t.Fatalf("parse error: %s", err)
+	}
+	root, _ := g.NodeByLabel("root")
+	if g.OutDegree(root) != 2 {
+		t.Errorf("@p should contain nodes of nested instances, root degree = %d", g.OutDegree(root))
+	}
+	other, _ := g.NodeByLabel("other")
+	if g.OutDegree(other) != 1 {
+		t.Errorf("@p.a should contain one node, other degree = %d", g.OutDegree(other))
+	}
+}
+
+func TestBlocksErrors(t *testing.T) {
+	cases := []string{
+		"template t { x -> $nope }; i = t",
+		"@g = a; @g = b",
+		"template t {",
+		"template t x {",
+		"template t { a }; template t { b }",
+		"template t(a a) { x }",
+		"template t(a) { x }; i = t",
+		"template t { x }; i = t(a)",
+		"template t { x }; @i = t; i = t",
+		"template t { template u { x }; }",
+		"template t { i = t }; i = t",
+		"include x.gra",
+	}
+
+	for i, text := range cases {
+		g := gorka.New()
+		err := gralang.Parse(g, text)
+		if err == nil {
+			t.Errorf("#%d: '%s' parsed without error", i, text)
+		}
+	}
+}
+
+func TestBlocksOldLabels(t *testing.T) {
+	type testcase struct {
+		text   string
+		labels []string
+	}
+	cases := []testcase{
+		testcase{"include", []string{"include"}},
+		testcase{"include -> x", []string{"include", "x"}},
+		testcase{"template; template -> }", []string{"template", "}"}},
+		testcase{"}", []string{"}"}},
+		testcase{"$x -> $y", []string{"$x", "$y"}},
+		testcase{"a = b -> c", []string{"a", "=", "b", "c"}},
+		testcase{"\\include a.gra", []string{"include", "a.gra"}},
+		testcase{"\\@home -> \\}", []string{"@home", "}"}},
+		testcase{"a \\= b", []string{"a", "=", "b"}},
+		testcase{"template t { \\$x -> \\} }; i = t", []string{"i.$x", "i.}"}},
+		testcase{"a -> @b", []string{"a", "@b"}},
+		testcase{"x = y", []string{"x", "=", "y"}},
+		testcase{"a = b c", []string{"a", "=", "b", "c"}},
+		testcase{"@x", []string{"@x"}},
+		testcase{"@ = a; @g =", []string{"@", "=", "a", "@g"}},
+		testcase{"@g = a; x -> @g; x = @g", []string{"a", "x", "="}},
+	}
+
+	for i, c := range cases {
+		g := gorka.New()
+		if err := gralang.Parse(g, c.text); err != nil {
+			t.Errorf("#%d: parse error: %s", i, err)
+			continue
+		}
+		if g.NodesCount() != len(c.labels) {
+			t.Errorf("#%d: wrong nodes count - %d, expected %d", i, g.NodesCount(), len(c.labels))
+		}
+		for _, l := range c.labels {
+			if _, ok := g.NodeByLabel(l); !ok {
+				t.Errorf("#%d: no node with label %s", i, l)
+			}
+		}
+	}
+}
+
+func TestBlocksInstanceCollision(t *testing.T) {
+	g := gorka.New()
+	err := gralang.Parse(g, "@i = x; template t { a -> b }; i = t")
+	if err == nil {
+		t.Fatalf("instance named as existing group should fail")
+	}
+	if g.NodesCount() != 1 || g.EdgesCount() != 0 {
+		t.Errorf("failed instance changed the graph:\n%s", g)
+	}
+}
+
+func TestInclude(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.gra":       {Data: []byte("include lib/rack.gra\nr1 = rack(core)\n")},
+		"lib/rack.gra":   {Data: []byte("include common.gra\ntemplate rack(up) {\ntor -> $up @hosts\n}\n")},
+		"lib/common.gra": {Data: []byte("@hosts = h1 h2\n")},
+		"loop.gra":       {Data: []byte("a -> b\ninclude loop.gra\n")},
+		"open.gra":       {Data: []byte("template t {\n")},
+	}
+
+	g := gorka.New()
+	d := gralang.NewDecoder(strings.NewReader("include main.gra\nx -> r1.tor\n"))
+	d.SetIncludeFS(fsys)
+	if err := d.Decode(g); err != nil {
+		t.Fatalf("decode error: %s", err)
+	}
+	if g.NodesCount() != 5 || g.EdgesCount() != 4 {
+		t.Errorf("wrong graph:\n%s", g)
+	}
+
+	for _, name := range []string{"loop.gra", "open.gra", "missing.gra"} {
+		d := gralang.NewDecoder(strings.NewReader("include " + name + "\nx\n"))
+		d.SetIncludeFS(fsys)
+		if err := d.Decode(gorka.New()); err == nil {
+			t.Errorf("include %s should fail", name)
+		}
+	}
+}
+
+func TestParseFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, text string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("main.gra", "include part.gra\na -> b\n")
+	write("part.gra", "b -> c\n")
+
+	g := gorka.New()
+	if err := gralang.ParseFile(g, filepath.Join(dir, "main.gra")); err != nil {
+		t.Fatalf("parse error: %s", err)
+	}
+	if g.NodesCount() != 3 || g.EdgesCount() != 2 {
+		t.Errorf("wrong graph:\n%s", g)
+	}
+
+	if err := gralang.ParseFile(gorka.New(), filepath.Join(dir, "missing.gra")); err == nil {
+		t.Errorf("missing file should fail")
+	}
+}
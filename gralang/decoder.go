@@ -0,0 +1,140 @@
+package gralang
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/iimos/gorka/types"
+)
+
+// DefaultMaxLineSize is the longest line a Decoder accepts by default
+const DefaultMaxLineSize = 64 * 1024
+
+// Progress tells how much of the input a Decoder has consumed
+type Progress struct {
+	Bytes int64 // bytes read so far
+	Lines int   // lines parsed so far
+}
+
+// Decoder reads Gralang from an input stream line by line, so memory usage
+// is bounded by the longest line rather than by the size of the input.
+type Decoder struct {
+	r        *bufio.Reader
+	p        *parser
+	progress Progress
+
+	notify    func(p Progress)
+	every     int64
+	nextPoint int64
+
+	fsys fs.FS
+}
+
+// NewDecoder returns a decoder that reads from r
+func NewDecoder(r io.Reader) *Decoder {
+	return NewDecoderSize(r, DefaultMaxLineSize)
+}
+
+// NewDecoderSize returns a decoder that reads from r and accepts lines up to size bytes long
+func NewDecoderSize(r io.Reader, size int) *Decoder {
+	return &Decoder{r: bufio.NewReaderSize(r, size)}
+}
+
+// SetIncludeFS makes include statements read files from fsys, without it
+// includes are rejected. Include paths are relative to the root of fsys.
+func (d *Decoder) SetIncludeFS(fsys fs.FS) {
+	d.fsys = fsys
+}
+
+// OnProgress sets fn to be called each time at least every bytes are consumed
+// and once more when the input is exhausted. Zero every means only the final call.
+func (d *Decoder) OnProgress(every int64, fn func(p Progress)) {
+	d.notify = fn
+	d.every = every
+	d.nextPoint = d.progress.Bytes + every
+}
+
+// Progress returns how much of the input is consumed
+func (d *Decoder) Progress() Progress {
+	return d.progress
+}
+
+// Decode reads the input until EOF and fills the graph.
+// Errors are prefixed with the number of the line they occurred on.
+func (d *Decoder) Decode(g types.Graph) error {
+	if g == nil {
+		return errors.New("graph is empty")
+	}
+	if d.p == nil || d.p.g != g {
+		d.p = newParser(g)
+		d.p.fsys = d.fsys
+	}
+
+	if err := d.decode(); err != nil {
+		return err
+	}
+	if err := d.p.finish(); err != nil {
+		return fmt.Errorf("line %d: %s", d.progress.Lines, err)
+	}
+	return nil
+}
+
+// decode feeds the parser line by line
+func (d *Decoder) decode() error {
+	for {
+		line, err := d.r.ReadSlice('\n')
+		if err == bufio.ErrBufferFull {
+			return fmt.Errorf("line %d: line is longer than %d bytes", d.progress.Lines+1, d.r.Size())
+		}
+		if err != nil && err != io.EOF {
+			return err
+		}
+
+		if len(line) > 0 {
+			d.progress.Bytes += int64(len(line))
+			d.progress.Lines++
+			if perr := d.p.parse(string(line)); perr != nil {
+				return fmt.Errorf("line %d: %s", d.progress.Lines, perr)
+			}
+			if d.notify != nil && d.every > 0 && d.progress.Bytes >= d.nextPoint {
+				d.nextPoint = d.progress.Bytes + d.every
+				d.notify(d.progress)
+			}
+		}
+
+		if err == io.EOF {
+			if d.notify != nil {
+				d.notify(d.progress)
+			}
+			return nil
+		}
+	}
+}
+
+// ParseFile reads Gralang from the named file and fills the graph.
+// Included files are read from the OS file system and looked up relative
+// to the file that includes them.
+func ParseFile(g types.Graph, name string) error {
+	if g == nil {
+		return errors.New("graph is empty")
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	d := NewDecoder(f)
+	d.p = newParser(g)
+	d.p.osFiles = true
+	d.p.dir = filepath.Dir(name)
+	if err := d.Decode(g); err != nil {
+		return fmt.Errorf("%s: %s", name, err)
+	}
+	return nil
+}
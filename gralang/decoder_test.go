@@ -0,0 +1,137 @@
+package gralang_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/iimos/gorka"
+	"github.com/iimos/gorka/gralang"
+	"github.com/iimos/gorka/types"
+)
+
+func TestDecoder(t *testing.T) {
+	cases := []string{
+		"",
+		"a",
+		"a -> b",
+		"a -> b\nb -> a",
+		"a b -- c d",
+		";;;;a -> b;;;",
+		"a -> b c ; b -> c ; c -- d \n d -> a c",
+		"\r\na -> b\r\nb -> c\r\n",
+		"йö -> Ы 漢字\n😞-> 😀\n",
+	}
+
+	for i, text := range cases {
+		expected := gorka.New()
+		if err := gralang.Parse(expected, text); err != nil {
+			t.Errorf("#%d: parse error: %s", i, err)
+			continue
+		}
+
+		g := gorka.New()
+		if err := gralang.NewDecoder(strings.NewReader(text)).Decode(g); err != nil {
+			t.Errorf("#%d: decode error: %s", i, err)
+			continue
+		}
+
+		if !sameGraph(g, expected) {
+			t.Errorf("#%d: decoded graph differs from parsed:\n%s\nexpected:\n%s", i, g, expected)
+		}
+	}
+}
+
+// sameGraph compares graphs by node labels and edges between them
+func sameGraph(a, b types.Graph) bool {
+	if a.NodesCount() != b.NodesCount() || a.EdgesCount() != b.EdgesCount() {
+		return false
+	}
+	same := true
+	a.NodeIter(func(n types.Node) bool {
+		bn, ok := b.NodeByLabel(n.Label())
+		if !ok {
+			same = false
+			return false
+		}
+		a.NodeEdgeIter(n, func(e types.Edge) bool {
+			bd, ok := b.NodeByLabel(e.Dst().Label())
+			same = ok && b.HasEdgeBetween(bn, bd)
+			return same
+		})
+		return same
+	})
+	return same
+}
+
+func TestDecoderErrors(t *testing.T) {
+	type tcase struct {
+		text string
+		line string
+	}
+	cases := []tcase{
+		tcase{"a -> b\na <-> b", "line 2:"},
+		tcase{"a ->", "line 1:"},
+		tcase{"a\nb\n\n-- a", "line 4:"},
+	}
+
+	for i, c := range cases {
+		g := gorka.New()
+		err := gralang.NewDecoder(strings.NewReader(c.text)).Decode(g)
+		if err == nil {
+			t.Errorf("#%d: '%s' decoded without error", i, c.text)
+			continue
+		}
+		if !strings.HasPrefix(err.Error(), c.line) {
+			t.Errorf("#%d: error '%s' should start with '%s'", i, err, c.line)
+		}
+	}
+
+	if err := gralang.NewDecoder(strings.NewReader("")).Decode(nil); err == nil {
+		t.Errorf("decoder should not accept nil graphs")
+	}
+}
+
+func TestDecoderLongLine(t *testing.T) {
+	text := "a -> b\n" + strings.Repeat("x ", 100) + "\n"
+	g := gorka.New()
+	err := gralang.NewDecoderSize(strings.NewReader(text), 32).Decode(g)
+	if err == nil || !strings.HasPrefix(err.Error(), "line 2:") {
+		t.Errorf("too long line should be reported, got: %v", err)
+	}
+}
+
+func TestDecoderProgress(t *testing.T) {
+	text := strings.Repeat("a -> b\n", 10)
+	calls := []gralang.Progress{}
+
+	d := gralang.NewDecoder(strings.NewReader(text))
+	d.OnProgress(21, func(p gralang.Progress) {
+		calls = append(calls, p)
+	})
+	if err := d.Decode(gorka.New()); err != nil {
+		t.Fatalf("decode error: %s", err)
+	}
+
+	expected := []gralang.Progress{{21, 3}, {42, 6}, {63, 9}, {70, 10}}
+	if len(calls) != len(expected) {
+		t.Fatalf("wrong progress calls: got %v, expected %v", calls, expected)
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Errorf("#%d: wrong progress: got %v, expected %v", i, calls[i], expected[i])
+		}
+	}
+	if d.Progress() != expected[len(expected)-1] {
+		t.Errorf("wrong final progress: %v", d.Progress())
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	text := strings.Repeat("a -- b c d\nb -> c d\nЙ -> 漢\n", 100)
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		g := gorka.New()
+		b.StartTimer()
+		gralang.NewDecoder(strings.NewReader(text)).Decode(g)
+	}
+}
@@ -0,0 +1,106 @@
+package gralang
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/iimos/gorka/internal/graphutil"
+	"github.com/iimos/gorka/types"
+)
+
+// labelsPerLine limits node lists written by Write, so that lines stay
+// well below DefaultMaxLineSize
+const labelsPerLine = 16
+
+// Write writes the graph in Gralang. The first lines list all nodes in
+// NodeIter order, so parsing the output creates nodes in the same order,
+// and the following lines hold outgoing edges of every node.
+//
+// Gralang has no weights and labels are its only node names, so graphs
+// with edge weights other than 1, unlabeled nodes, self-loops or labels
+// with spaces, edge or line break characters are reported as errors.
+// Labels that would be read as keywords, groups or parameters are escaped.
+func Write(w io.Writer, g types.Graph) error {
+	if g == nil {
+		return errors.New("graph is empty")
+	}
+
+	var labels []string
+	var err error
+	g.NodeIter(func(n types.Node) bool {
+		if err = checkLabel(n.Label()); err != nil {
+			err = fmt.Errorf("node %d: %s", n.ID(), err)
+			return false
+		}
+		labels = append(labels, escape(n.Label()))
+		g.NodeEdgeIter(n, func(e types.Edge) bool {
+			switch {
+			case e.Dst().ID() == n.ID():
+				err = fmt.Errorf("node %s: self-loops are not supported", n.Label())
+			case e.Wieght() != 1:
+				err = fmt.Errorf("edge %s -> %s: weight %v is not supported", n.Label(), e.Dst().Label(), e.Wieght())
+			}
+			return err == nil
+		})
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+
+	b := bufio.NewWriter(w)
+	writeList(b, "", labels)
+	g.NodeIter(func(n types.Node) bool {
+		edges := graphutil.SortedEdges(g, n)
+		dst := make([]string, len(edges))
+		for i, e := range edges {
+			dst[i] = escape(e.Dst().Label())
+		}
+		writeList(b, escape(n.Label())+" -> ", dst)
+		return true
+	})
+	return b.Flush()
+}
+
+// writeList writes labels by lines of labelsPerLine, each line starts with prefix
+func writeList(b *bufio.Writer, prefix string, labels []string) {
+	for i, l := range labels {
+		if i%labelsPerLine == 0 {
+			if i > 0 {
+				b.WriteByte('\n')
+			}
+			b.WriteString(prefix)
+		} else {
+			b.WriteByte(' ')
+		}
+		b.WriteString(l)
+	}
+	if len(labels) > 0 {
+		b.WriteByte('\n')
+	}
+}
+
+// checkLabel reports whether the label can be written in Gralang
+func checkLabel(label string) error {
+	if label == "" {
+		return errors.New("unlabeled nodes are not supported")
+	}
+	for i := 0; i < len(label); i++ {
+		if lext[label[i]] != lexNode {
+			return fmt.Errorf("label %q has character %q", label, label[i])
+		}
+	}
+	return nil
+}
+
+// escape returns the label as it is written in Gralang
+func escape(label string) string {
+	switch {
+	case label[0] == '@' || label[0] == '$' || label[0] == '\\',
+		label == "include" || label == "template" || label == "}" || label == "=":
+		return `\` + label
+	}
+	return label
+}
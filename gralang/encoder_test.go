@@ -0,0 +1,71 @@
+package gralang_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/iimos/gorka"
+	"github.com/iimos/gorka/gralang"
+)
+
+func TestWrite(t *testing.T) {
+	cases := []string{
+		"",
+		"a",
+		"a -> b c; c -- d; e",
+		"@g = x y; a -> @g; y -> a",
+		"template pair { a -- b }; p = pair; q = pair; p.a -> q.b",
+		"include; \\include template \\= }; \\@a -> \\$b \\\\c",
+	}
+
+	for i, text := range cases {
+		g := gorka.New()
+		if err := gralang.Parse(g, text); err != nil {
+			t.Errorf("#%d: parse error: %s", i, err)
+			continue
+		}
+		var b strings.Builder
+		if err := gralang.Write(&b, g); err != nil {
+			t.Errorf("#%d: write error: %s", i, err)
+			continue
+		}
+		g2 := gorka.New()
+		if err := gralang.Parse(g2, b.String()); err != nil {
+			t.Errorf("#%d: parse error of written text: %s\n%s", i, err, b.String())
+			continue
+		}
+		if !sameGraph(g, g2) {
+			t.Errorf("#%d: graph changed:\n%s\nexpected:\n%s", i, g2, g)
+		}
+	}
+}
+
+func TestWriteErrors(t *testing.T) {
+	labels := []string{"", "a b", "a-b", "a;b", "a\nb"}
+	for i, l := range labels {
+		g := gorka.New()
+		g.NewNode(l)
+		if err := gralang.Write(&strings.Builder{}, g); err == nil {
+			t.Errorf("#%d: no error for label %q", i, l)
+		}
+	}
+
+	g := gorka.New()
+	a, _ := g.NewNode("a")
+	b, _ := g.NewNode("b")
+	g.AddEdge(a, b, 0.5)
+	if err := gralang.Write(&strings.Builder{}, g); err == nil {
+		t.Errorf("no error for weighted edge")
+	}
+
+	g = gorka.New()
+	a, _ = g.NewNode("a")
+	g.AddEdge(a, a, 1)
+	if err := gralang.Write(&strings.Builder{}, g); err == nil {
+		t.Errorf("no error for self-loop")
+	}
+
+	if err := gralang.Write(&strings.Builder{}, nil); err == nil {
+		t.Errorf("no error for nil graph")
+	}
+}
@@ -16,6 +16,7 @@ const (
 const (
 	edgeDir = iota
 	edgeBi
+	edgeRev
 )
 
 var lext = [256]uint8{
@@ -33,28 +34,60 @@ var lext = [256]uint8{
 	';':  lexEol,
 }
 
-// Parse parses Gralang and fill Graph
+// Parse parses Gralang and fill Graph. It never reads files,
+// include statements are rejected.
 func Parse(g types.Graph, s string) error {
 	if g == nil {
 		return errors.New("graph is empty")
 	}
+	p := newParser(g)
+	if err := p.parse(s); err != nil {
+		return err
+	}
+	return p.finish()
+}
 
+// parse splits s into statements and applies them to the graph
+func (p *parser) parse(s string) error {
 	for len(s) > 0 {
 		sz := readLn(s)
 		s = s[sz:]
 
-		lnodes, sz := readNodeList(s)
-		s = s[sz:]
+		stmt := readStatement(s)
+		s = s[len(stmt):]
 
-		edge := readSeq(s, lexEdge)
-		if edge == "" {
-			// case when line consists only of node list
-			for _, l := range lnodes {
-				obtainNode(g, l)
-			}
-			continue
+		if err := p.statement(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// edges handles a statement made of node lists joined by edges
+func (p *parser) edges(s string) error {
+	labels, sz := readNodeList(s)
+	s = s[sz:]
+
+	lnodes, err := p.resolveList(labels)
+	if err != nil {
+		return err
+	}
+
+	edge := readSeq(s, lexEdge)
+	if edge == "" {
+		// case when line consists only of node list
+		for _, l := range lnodes {
+			obtainNode(p.g, l)
 		}
+		return nil
+	}
+
+	if len(labels) == 0 {
+		return errors.New("empty edge source")
+	}
 
+	// a chain like `a -> b <- c -- d` connects every pair of adjacent node lists
+	for edge != "" {
 		s = s[len(edge):]
 
 		edgelex, err := edgeType(edge)
@@ -62,35 +95,46 @@ func Parse(g types.Graph, s string) error {
 			return err
 		}
 
-		if len(lnodes) == 0 {
-			return errors.New("empty edge source")
-		}
-
-		rnodes, sz := readNodeList(s)
-		if len(rnodes) == 0 {
+		labels, sz := readNodeList(s)
+		if len(labels) == 0 {
 			return errors.New("empty edge destination")
 		}
 
 		s = s[sz:]
 
-		for _, ll := range lnodes {
-			ln := obtainNode(g, ll)
-			for _, rl := range rnodes {
-				if ll != rl {
-					rn := obtainNode(g, rl)
-					switch edgelex {
-					case edgeBi:
-						g.AddBiEdge(ln, rn, 1)
-					case edgeDir:
-						g.AddEdge(ln, rn, 1)
-					default:
-						panic(fmt.Sprintf("gralang.Parse: unknown edge lexem: %d", edgelex))
-					}
+		rnodes, err := p.resolveList(labels)
+		if err != nil {
+			return err
+		}
+
+		connect(p.g, lnodes, rnodes, edgelex)
+
+		lnodes = rnodes
+		edge = readSeq(s, lexEdge)
+	}
+	return nil
+}
+
+// connect adds edges between each pair of nodes from lnodes and rnodes
+func connect(g types.Graph, lnodes, rnodes []string, edgelex int) {
+	for _, ll := range lnodes {
+		ln := obtainNode(g, ll)
+		for _, rl := range rnodes {
+			if ll != rl {
+				rn := obtainNode(g, rl)
+				switch edgelex {
+				case edgeBi:
+					g.AddBiEdge(ln, rn, 1)
+				case edgeDir:
+					g.AddEdge(ln, rn, 1)
+				case edgeRev:
+					g.AddEdge(rn, ln, 1)
+				default:
+					panic(fmt.Sprintf("gralang.Parse: unknown edge lexem: %d", edgelex))
 				}
 			}
 		}
 	}
-	return nil
 }
 
 func obtainNode(g types.Graph, label string) types.Node {
@@ -143,6 +187,16 @@ func readLn(s string) int {
 	return len(s)
 }
 
+func readStatement(s string) string {
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if lext[ch] == lexEol {
+			return s[:i]
+		}
+	}
+	return s
+}
+
 func readSeq(s string, lextype int) string {
 	i, n := 0, len(s)
 	for ; i < n; i++ {
@@ -160,6 +214,8 @@ func edgeType(s string) (int, error) {
 		return edgeBi, nil
 	case "->":
 		return edgeDir, nil
+	case "<-":
+		return edgeRev, nil
 	}
-	return 0, fmt.Errorf("Wrong edge syntax: '%s'. Expected '--' or '->'", s)
+	return 0, fmt.Errorf("Wrong edge syntax: '%s'. Expected '--', '->' or '<-'", s)
 }
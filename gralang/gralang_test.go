@@ -1,8 +1,9 @@
-package gralang
+package gralang_test
 
 import (
 	"testing"
 	"github.com/iimos/gorka"
+	"github.com/iimos/gorka/gralang"
 )
 
 func TestGralang(t *testing.T) {
@@ -79,11 +80,35 @@ func TestGralang(t *testing.T) {
 			e{"a", "b"}, e{"a", "c"},
 			e{"b", "c"}, e{"c", "d"}, e{"d", "a"}, e{"d", "c"},
 		}},
+		testcase{"a <- b", 2, 1, []e{
+			e{"b", "a"},
+		}},
+		testcase{"a b <- c", 3, 2, []e{
+			e{"c", "a"}, e{"c", "b"},
+		}},
+		testcase{"a -> b -> c -> d", 4, 3, []e{
+			e{"a", "b"}, e{"b", "c"}, e{"c", "d"},
+		}},
+		testcase{"a->b->c", 3, 2, []e{
+			e{"a", "b"}, e{"b", "c"},
+		}},
+		testcase{"a -> b <- c -- d", 4, 4, []e{
+			e{"a", "b"}, e{"c", "b"}, e{"c", "d"}, e{"d", "c"},
+		}},
+		testcase{"a -> b c -> d", 4, 4, []e{
+			e{"a", "b"}, e{"a", "c"}, e{"b", "d"}, e{"c", "d"},
+		}},
+		testcase{"a -> b -> a", 2, 2, []e{
+			e{"a", "b"}, e{"b", "a"},
+		}},
+		testcase{"a -> b -> c; c <- d <- e", 5, 4, []e{
+			e{"a", "b"}, e{"b", "c"}, e{"d", "c"}, e{"e", "d"},
+		}},
 	}
 
 	for i, c := range cases {
 		g := gorka.New()
-		err := Parse(g, c.text)
+		err := gralang.Parse(g, c.text)
 		if err != nil {
 			t.Errorf("#%d: parse error: %s", i, err)
 			return
@@ -120,11 +145,16 @@ func TestGralangSyntaxErrors(t *testing.T) {
 		"a --",
 		"-- a",
 		"-> a",
+		"<- a",
+		"a <-- b",
+		"a -> b ->",
+		"a -> -> b",
+		"a -> b <-; c",
 	}
 
 	for i, text := range cases {
 		g := gorka.New()
-		err := Parse(g, text)
+		err := gralang.Parse(g, text)
 		if err == nil {
 			t.Errorf("#%d: '%s' parsed without error", i, text)
 			return
@@ -133,7 +163,7 @@ func TestGralangSyntaxErrors(t *testing.T) {
 }
 
 func TestBadGraph(t *testing.T) {
-	err := Parse(nil, "")
+	err := gralang.Parse(nil, "")
 	if err == nil {
 		t.Errorf("parse should not accept nil graphs")
 	}
@@ -144,7 +174,7 @@ func BenchmarkParse(b *testing.B) {
 		b.StopTimer()
 		g := gorka.New()
 		b.StartTimer()
-		Parse(g, `
+		gralang.Parse(g, `
 			a -- b c d
 			b -> c d
 			Й -> 漢
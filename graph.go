@@ -121,6 +121,15 @@ func (g *graph) NodeEdgeIter(n Node, cb func(e Edge) bool) {
 	}
 }
 
+// NodeInEdgeIter calls cb for each incoming edge of the node. Stops when cb returns false.
+func (g *graph) NodeInEdgeIter(n Node, cb func(e Edge) bool) {
+	for _, e := range g.edgesIn[n.ID()] {
+		if !cb(e) {
+			break
+		}
+	}
+}
+
 // NeighbourIter calls cb for each neighbor of the the given node. Stops when cb returns false.
 func (g *graph) NeighbourIter(n Node, cb func(n Node) bool) {
 	for _, e := range g.edgesOut[n.ID()] {
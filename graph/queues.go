@@ -6,7 +6,7 @@ import (
 
 // NodeQueue is a thread safe FIFO queue
 type NodeQueue struct {
-	q queue.Queue
+	q queue.AnyQueue
 }
 
 // Len returns number of nodes in the queue
@@ -30,7 +30,7 @@ func (q *NodeQueue) Pop() Node {
 
 // NodeStack is a thread safe LIFO stack
 type NodeStack struct {
-	q queue.Stack
+	q queue.AnyStack
 }
 
 // Len returns number of nodes in the stack
@@ -0,0 +1,119 @@
+package graphml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Namespace is the GraphML XML namespace
+const Namespace = "http://graphml.graphdrawing.org/xmlns"
+
+// Data holds attribute values by attribute name. Values are typed after
+// the key declaration: bool for boolean, int for int, int64 for long,
+// float32 for float, float64 for double and string for string.
+type Data map[string]interface{}
+
+// Key is a GraphML attribute declaration
+type Key struct {
+	ID      string
+	For     string // node, edge, graph or all
+	Name    string
+	Type    string // boolean, int, long, float, double or string
+	Default interface{}
+}
+
+type xmlGraphML struct {
+	XMLName xml.Name   `xml:"graphml"`
+	Xmlns   string     `xml:"xmlns,attr,omitempty"`
+	Keys    []xmlKey   `xml:"key"`
+	Graphs  []xmlGraph `xml:"graph"`
+}
+
+type xmlKey struct {
+	ID      string  `xml:"id,attr"`
+	For     string  `xml:"for,attr,omitempty"`
+	Name    string  `xml:"attr.name,attr,omitempty"`
+	Type    string  `xml:"attr.type,attr,omitempty"`
+	Default *string `xml:"default"`
+}
+
+type xmlGraph struct {
+	ID          string    `xml:"id,attr,omitempty"`
+	EdgeDefault string    `xml:"edgedefault,attr"`
+	Data        []xmlData `xml:"data"`
+	Nodes       []xmlNode `xml:"node"`
+	Edges       []xmlEdge `xml:"edge"`
+}
+
+type xmlNode struct {
+	ID     string     `xml:"id,attr"`
+	Data   []xmlData  `xml:"data"`
+	Graphs []xmlGraph `xml:"graph"` // nested graphs
+}
+
+type xmlEdge struct {
+	ID       string    `xml:"id,attr,omitempty"`
+	Source   string    `xml:"source,attr"`
+	Target   string    `xml:"target,attr"`
+	Directed string    `xml:"directed,attr,omitempty"`
+	Data     []xmlData `xml:"data"`
+}
+
+type xmlData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// typeOf returns GraphML type of the value
+func typeOf(v interface{}) (string, error) {
+	switch v.(type) {
+	case bool:
+		return "boolean", nil
+	case int:
+		return "int", nil
+	case int64:
+		return "long", nil
+	case float32:
+		return "float", nil
+	case float64:
+		return "double", nil
+	case string:
+		return "string", nil
+	}
+	return "", fmt.Errorf("unsupported attribute type %T", v)
+}
+
+// parseValue converts the text to a value of the GraphML type
+func parseValue(typ, s string) (interface{}, error) {
+	if typ != "" && typ != "string" {
+		s = strings.TrimSpace(s)
+	}
+	switch typ {
+	case "boolean":
+		return strconv.ParseBool(s)
+	case "int":
+		return strconv.Atoi(s)
+	case "long":
+		return strconv.ParseInt(s, 10, 64)
+	case "float":
+		f, err := strconv.ParseFloat(s, 32)
+		return float32(f), err
+	case "double":
+		return strconv.ParseFloat(s, 64)
+	case "", "string":
+		return s, nil
+	}
+	return nil, fmt.Errorf("unknown attribute type '%s'", typ)
+}
+
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
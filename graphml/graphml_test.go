@@ -0,0 +1,214 @@
+package graphml
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/iimos/gorka"
+	"github.com/iimos/gorka/gralang"
+	"github.com/iimos/gorka/types"
+)
+
+func TestParse(t *testing.T) {
+	doc := `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="d0" for="node" attr.name="color" attr.type="string"><default>yellow</default></key>
+  <key id="d1" for="edge" attr.name="weight" attr.type="double"/>
+  <key id="d2" for="node" attr.name="size" attr.type="int"/>
+  <key id="d3" for="graph" attr.name="title" attr.type="string"/>
+  <graph id="G" edgedefault="undirected">
+    <data key="d3">net</data>
+    <node id="n0"><data key="d0">green</data><data key="d2"> 5 </data></node>
+    <node id="n1"/>
+    <node id="n2">
+      <graph id="n2:" edgedefault="directed">
+        <node id="n2::n0"/>
+        <edge source="n2::n0" target="n0"/>
+      </graph>
+    </node>
+    <edge id="e0" source="n0" target="n1"><data key="d1">1.5</data></edge>
+    <edge id="e1" source="n1" target="n2" directed="true"/>
+  </graph>
+</graphml>`
+
+	g := gorka.New()
+	d := NewDecoder(strings.NewReader(doc))
+	nodes := map[string]Data{}
+	d.OnNode(func(n types.Node, attrs Data) {
+		nodes[n.Label()] = attrs
+	})
+	edges := 0
+	d.OnEdge(func(e types.Edge, attrs Data) {
+		edges++
+	})
+	if err := d.Decode(g); err != nil {
+		t.Fatalf("decode error: %s", err)
+	}
+
+	if g.NodesCount() != 4 {
+		t.Errorf("wrong nodes count: %d", g.NodesCount())
+	}
+	if g.EdgesCount() != 4 {
+		t.Errorf("wrong edges count: %d", g.EdgesCount())
+	}
+	if edges != 3 {
+		t.Errorf("wrong edge callbacks count: %d", edges)
+	}
+
+	type e struct {
+		a, b string
+		w    float32
+	}
+	for _, c := range []e{{"n0", "n1", 1.5}, {"n1", "n0", 1.5}, {"n1", "n2", 1}, {"n2::n0", "n0", 1}} {
+		a, _ := g.NodeByLabel(c.a)
+		found := false
+		g.NodeEdgeIter(a, func(edge types.Edge) bool {
+			if edge.Dst().Label() == c.b {
+				found = edge.Wieght() == c.w
+			}
+			return true
+		})
+		if !found {
+			t.Errorf("no edge %s->%s of weight %f", c.a, c.b, c.w)
+		}
+	}
+
+	if nodes["n0"]["color"] != "green" || nodes["n0"]["size"] != 5 {
+		t.Errorf("wrong attrs of n0: %v", nodes["n0"])
+	}
+	if nodes["n1"]["color"] != "yellow" {
+		t.Errorf("default attr is not applied to n1: %v", nodes["n1"])
+	}
+
+	h := d.Header()
+	if h.ID != "G" || h.Directed || h.Attrs["title"] != "net" {
+		t.Errorf("wrong header: %+v", h)
+	}
+	if len(d.Keys()) != 4 || d.Keys()[1].Type != "double" {
+		t.Errorf("wrong keys: %+v", d.Keys())
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []string{
+		"",
+		"<graphml></graphml>",
+		"<graphml><graph><node/></graph></graphml>",
+		`<graphml><graph><edge source="a"/></graph></graphml>`,
+		`<graphml><graph><node id="a"><data key="x">1</data></node></graph></graphml>`,
+		`<graphml><key id="x" attr.type="int"/><graph><node id="a"><data key="x">z</data></node></graph></graphml>`,
+		`<graphml><key id="w" attr.name="weight" attr.type="boolean"/><graph><edge source="a" target="b"><data key="w">true</data></edge></graph></graphml>`,
+		`<graphml><graph>`,
+	}
+	for i, text := range cases {
+		if err := Parse(gorka.New(), text); err == nil {
+			t.Errorf("#%d: '%s' parsed without error", i, text)
+		}
+	}
+	if err := Parse(nil, "<graphml/>"); err == nil {
+		t.Errorf("parse should not accept nil graphs")
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	src := gorka.New()
+	gralang.Parse(src, "a -> b c; b -- c")
+	a, _ := src.NodeByLabel("a")
+	b, _ := src.NodeByLabel("b")
+	c, _ := src.NodeByLabel("c")
+	d, _ := src.NewNode("<d&\"e\">")
+	src.AddEdge(a, b, 2.5)
+	src.AddEdge(c, d, 1)
+
+	for _, undirected := range []bool{false, true} {
+		var s strings.Builder
+		opt := &Options{
+			ID:         "g",
+			Undirected: undirected,
+			Weights:    true,
+			GraphAttrs: Data{"title": "test"},
+			NodeAttrs: func(n types.Node) Data {
+				return Data{"len": len(n.Label()), "big": n.ID() > 2}
+			},
+			EdgeAttrs: func(e types.Edge) Data {
+				return Data{"name": e.String()}
+			},
+		}
+		if err := Write(&s, src, opt); err != nil {
+			t.Fatalf("undirected=%v: write error: %s", undirected, err)
+		}
+
+		g := gorka.New()
+		d := NewDecoder(strings.NewReader(s.String()))
+		d.OnNode(func(n types.Node, attrs Data) {
+			if attrs["len"] != len(n.Label()) || attrs["big"] != (n.ID() > 2) {
+				t.Errorf("undirected=%v: wrong attrs of %s: %v", undirected, n, attrs)
+			}
+		})
+		d.OnEdge(func(e types.Edge, attrs Data) {
+			if attrs["weight"] != e.Wieght() {
+				t.Errorf("undirected=%v: wrong weight of %s: %v", undirected, e, attrs)
+			}
+		})
+		if err := d.Decode(g); err != nil {
+			t.Errorf("undirected=%v: parse error: %s\n%s", undirected, err, s.String())
+			continue
+		}
+
+		if d.Header().Directed == undirected || d.Header().Attrs["title"] != "test" {
+			t.Errorf("undirected=%v: wrong header %+v", undirected, d.Header())
+		}
+		if g.NodesCount() != src.NodesCount() {
+			t.Errorf("undirected=%v: wrong nodes count %d", undirected, g.NodesCount())
+		}
+		expected := src.EdgesCount()
+		if undirected {
+			expected = 8
+		}
+		if g.EdgesCount() != expected {
+			t.Errorf("undirected=%v: wrong edges count %d, expected %d", undirected, g.EdgesCount(), expected)
+		}
+
+		ga, _ := g.NodeByLabel("a")
+		gb, _ := g.NodeByLabel("b")
+		g.NodeEdgeIter(ga, func(e types.Edge) bool {
+			if e.Dst().ID() == gb.ID() && e.Wieght() != 2.5 {
+				t.Errorf("undirected=%v: wrong weight of a->b: %f", undirected, e.Wieght())
+			}
+			return true
+		})
+	}
+}
+
+func TestWriteTypeConflict(t *testing.T) {
+	g := gorka.New()
+	gralang.Parse(g, "a b")
+	err := Write(&strings.Builder{}, g, &Options{
+		NodeAttrs: func(n types.Node) Data {
+			if n.Label() == "a" {
+				return Data{"x": 1}
+			}
+			return Data{"x": "one"}
+		},
+	})
+	if err == nil {
+		t.Errorf("conflicting attribute types should fail")
+	}
+
+	err = Write(&strings.Builder{}, g, &Options{GraphAttrs: Data{"x": []int{}}})
+	if err == nil {
+		t.Errorf("unsupported attribute type should fail")
+	}
+}
+
+func TestWriteNameCollision(t *testing.T) {
+	g := gorka.New()
+	a, _ := g.NewNode("")
+	b, _ := g.NewNode("1")
+	g.AddEdge(a, b, 1)
+
+	var s strings.Builder
+	if err := Write(&s, g, nil); err == nil {
+		t.Errorf("unlabeled node 1 and node labeled \"1\" written without error:\n%s", s.String())
+	}
+}
@@ -0,0 +1,217 @@
+package graphml
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/iimos/gorka/internal/graphutil"
+	"github.com/iimos/gorka/types"
+)
+
+// Header describes the top level graph of a GraphML document
+type Header struct {
+	ID       string
+	Directed bool // edgedefault of the graph
+	Attrs    Data
+}
+
+// Decoder reads a GraphML document into a Graph.
+//
+// Node ids become node labels, nodes of nested graphs are added to the same
+// graph. Edge weight is taken from the "weight" attribute, otherwise it is 1.
+// Undirected edges are added in both directions.
+type Decoder struct {
+	r      io.Reader
+	header Header
+	keys   []Key
+	onNode func(n types.Node, attrs Data)
+	onEdge func(e types.Edge, attrs Data)
+}
+
+// NewDecoder returns a decoder that reads from r
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r}
+}
+
+// OnNode sets fn to be called for each node with its attributes, defaults included
+func (d *Decoder) OnNode(fn func(n types.Node, attrs Data)) {
+	d.onNode = fn
+}
+
+// OnEdge sets fn to be called for each edge with its attributes, defaults included.
+// For undirected edges it gets the edge from source to target.
+func (d *Decoder) OnEdge(fn func(e types.Edge, attrs Data)) {
+	d.onEdge = fn
+}
+
+// Header returns the top level graph description of the decoded document
+func (d *Decoder) Header() Header {
+	return d.header
+}
+
+// Keys returns attribute declarations of the decoded document
+func (d *Decoder) Keys() []Key {
+	return d.keys
+}
+
+// Decode reads the document and fills the graph
+func (d *Decoder) Decode(g types.Graph) error {
+	if g == nil {
+		return errors.New("graph is empty")
+	}
+
+	var doc xmlGraphML
+	if err := xml.NewDecoder(d.r).Decode(&doc); err != nil {
+		return err
+	}
+	if len(doc.Graphs) == 0 {
+		return errors.New("document has no graph")
+	}
+
+	keys := make(map[string]*Key, len(doc.Keys))
+	d.keys = make([]Key, 0, len(doc.Keys))
+	for _, xk := range doc.Keys {
+		k := Key{ID: xk.ID, For: xk.For, Name: xk.Name, Type: xk.Type}
+		if k.Name == "" {
+			k.Name = k.ID
+		}
+		if k.For == "" {
+			k.For = "all"
+		}
+		if xk.Default != nil {
+			v, err := parseValue(k.Type, *xk.Default)
+			if err != nil {
+				return fmt.Errorf("key %s: default: %s", k.ID, err)
+			}
+			k.Default = v
+		}
+		d.keys = append(d.keys, k)
+		keys[k.ID] = &d.keys[len(d.keys)-1]
+	}
+
+	r := reader{d: d, g: g, keys: keys}
+
+	top := doc.Graphs[0]
+	attrs, err := r.data("graph", top.Data)
+	if err != nil {
+		return err
+	}
+	d.header = Header{ID: top.ID, Directed: top.EdgeDefault != "undirected", Attrs: attrs}
+
+	for i := range doc.Graphs {
+		if err := r.graph(&doc.Graphs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Parse parses GraphML document and fills the graph
+func Parse(g types.Graph, s string) error {
+	return NewDecoder(strings.NewReader(s)).Decode(g)
+}
+
+type reader struct {
+	d    *Decoder
+	g    types.Graph
+	keys map[string]*Key
+}
+
+func (r *reader) graph(xg *xmlGraph) error {
+	directed := xg.EdgeDefault != "undirected"
+
+	for i := range xg.Nodes {
+		xn := &xg.Nodes[i]
+		if xn.ID == "" {
+			return errors.New("node without id")
+		}
+		n := graphutil.Obtain(r.g, xn.ID)
+		attrs, err := r.data("node", xn.Data)
+		if err != nil {
+			return fmt.Errorf("node %s: %s", xn.ID, err)
+		}
+		if r.d.onNode != nil {
+			r.d.onNode(n, attrs)
+		}
+		for j := range xn.Graphs {
+			if err := r.graph(&xn.Graphs[j]); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, xe := range xg.Edges {
+		if xe.Source == "" || xe.Target == "" {
+			return fmt.Errorf("edge %s: source and target are required", xe.ID)
+		}
+		attrs, err := r.data("edge", xe.Data)
+		if err != nil {
+			return fmt.Errorf("edge %s: %s", xe.ID, err)
+		}
+
+		weight := float32(1)
+		if v, ok := attrs["weight"]; ok {
+			if weight, ok = toWeight(v); !ok {
+				return fmt.Errorf("edge %s: weight is not a number: %v", xe.ID, v)
+			}
+		}
+
+		edgeDirected := directed
+		if xe.Directed != "" {
+			edgeDirected = xe.Directed == "true"
+		}
+
+		src, dst := graphutil.Obtain(r.g, xe.Source), graphutil.Obtain(r.g, xe.Target)
+		e := r.g.AddEdge(src, dst, weight)
+		if !edgeDirected {
+			r.g.AddEdge(dst, src, weight)
+		}
+		if r.d.onEdge != nil {
+			r.d.onEdge(e, attrs)
+		}
+	}
+	return nil
+}
+
+// data converts data elements to attributes adding defaults of the domain keys
+func (r *reader) data(domain string, list []xmlData) (Data, error) {
+	attrs := Data{}
+	for _, k := range r.d.keys {
+		if k.Default != nil && (k.For == domain || k.For == "all") {
+			attrs[k.Name] = k.Default
+		}
+	}
+	for _, xd := range list {
+		k, ok := r.keys[xd.Key]
+		if !ok {
+			return nil, fmt.Errorf("undeclared key %s", xd.Key)
+		}
+		v, err := parseValue(k.Type, xd.Value)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %s", xd.Key, err)
+		}
+		attrs[k.Name] = v
+	}
+	return attrs, nil
+}
+
+func toWeight(v interface{}) (float32, bool) {
+	switch v := v.(type) {
+	case int:
+		return float32(v), true
+	case int64:
+		return float32(v), true
+	case float32:
+		return v, true
+	case float64:
+		return float32(v), true
+	case string:
+		w, err := strconv.ParseFloat(v, 32)
+		return float32(w), err == nil
+	}
+	return 0, false
+}
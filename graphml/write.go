@@ -0,0 +1,162 @@
+package graphml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/iimos/gorka/internal/graphutil"
+	"github.com/iimos/gorka/types"
+)
+
+// Options controls how a graph is written
+type Options struct {
+	// ID is the graph id
+	ID string
+	// Undirected sets edgedefault="undirected", see graphutil.SkipOpposite
+	Undirected bool
+	// Weights writes edge weights as "weight" attribute of type float
+	Weights bool
+
+	// GraphAttrs are written as graph data
+	GraphAttrs Data
+	// NodeAttrs returns attributes of the node, may be nil
+	NodeAttrs func(n types.Node) Data
+	// EdgeAttrs returns attributes of the edge, may be nil
+	EdgeAttrs func(e types.Edge) Data
+}
+
+// Write writes the graph as GraphML document. Nodes are identified by labels,
+// unlabeled nodes by their IDs, so it fails when the ID of an unlabeled node
+// equals the label of another node. Keys are declared for every attribute name
+// met in the data, all values of an attribute must have the same type.
+func Write(w io.Writer, g types.Graph, opt *Options) error {
+	if opt == nil {
+		opt = &Options{}
+	}
+	if err := graphutil.CheckNames(g); err != nil {
+		return err
+	}
+
+	keys := newKeySet()
+	doc := xmlGraphML{Xmlns: Namespace}
+	graph := xmlGraph{ID: opt.ID, EdgeDefault: "directed"}
+	if opt.Undirected {
+		graph.EdgeDefault = "undirected"
+	}
+
+	var err error
+	if graph.Data, err = keys.data("graph", opt.GraphAttrs); err != nil {
+		return err
+	}
+
+	g.NodeIter(func(n types.Node) bool {
+		xn := xmlNode{ID: graphutil.LabelOrID(n)}
+		if opt.NodeAttrs != nil {
+			if xn.Data, err = keys.data("node", opt.NodeAttrs(n)); err != nil {
+				return false
+			}
+		}
+		graph.Nodes = append(graph.Nodes, xn)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	g.NodeIter(func(n types.Node) bool {
+		for _, e := range graphutil.SortedEdges(g, n) {
+			d := e.Dst()
+			if opt.Undirected && graphutil.SkipOpposite(g, n, d) {
+				continue
+			}
+
+			data := Data{}
+			if opt.Weights {
+				data["weight"] = e.Wieght()
+			}
+			if opt.EdgeAttrs != nil {
+				for k, v := range opt.EdgeAttrs(e) {
+					data[k] = v
+				}
+			}
+
+			xe := xmlEdge{
+				ID:     "e" + strconv.Itoa(len(graph.Edges)),
+				Source: graphutil.LabelOrID(n),
+				Target: graphutil.LabelOrID(d),
+			}
+			if xe.Data, err = keys.data("edge", data); err != nil {
+				return false
+			}
+			graph.Edges = append(graph.Edges, xe)
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	doc.Keys = keys.list
+	doc.Graphs = []xmlGraph{graph}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// keySet declares keys while data is written
+type keySet struct {
+	list []xmlKey
+	ids  map[string]int // for+name -> index in list
+}
+
+func newKeySet() *keySet {
+	return &keySet{ids: make(map[string]int)}
+}
+
+// data converts attributes to data elements declaring keys for new attribute names
+func (ks *keySet) data(domain string, attrs Data) ([]xmlData, error) {
+	if len(attrs) == 0 {
+		return nil, nil
+	}
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	res := make([]xmlData, 0, len(attrs))
+	for _, name := range names {
+		v := attrs[name]
+		typ, err := typeOf(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s attribute %s: %s", domain, name, err)
+		}
+
+		i, ok := ks.ids[domain+" "+name]
+		if !ok {
+			i = len(ks.list)
+			ks.ids[domain+" "+name] = i
+			ks.list = append(ks.list, xmlKey{
+				ID:   "d" + strconv.Itoa(i),
+				For:  domain,
+				Name: name,
+				Type: typ,
+			})
+		} else if ks.list[i].Type != typ {
+			return nil, fmt.Errorf("%s attribute %s has values of types %s and %s", domain, name, ks.list[i].Type, typ)
+		}
+		res = append(res, xmlData{Key: ks.list[i].ID, Value: formatValue(v)})
+	}
+	return res, nil
+}
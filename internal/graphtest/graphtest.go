@@ -0,0 +1,23 @@
+// Package graphtest holds helpers for tests of the packages that read graphs
+package graphtest
+
+import "github.com/iimos/gorka/types"
+
+// Weight returns the weight of the edge between nodes labeled a and b
+// and whether the edge exists
+func Weight(g types.Graph, a, b string) (float32, bool) {
+	na, ok := g.NodeByLabel(a)
+	if !ok {
+		return 0, false
+	}
+	var w float32
+	found := false
+	g.NodeEdgeIter(na, func(e types.Edge) bool {
+		if e.Dst().Label() == b {
+			w, found = e.Wieght(), true
+			return false
+		}
+		return true
+	})
+	return w, found
+}
@@ -0,0 +1,57 @@
+// Package graphutil holds helpers shared by the packages that read and write graphs
+package graphutil
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/iimos/gorka/types"
+)
+
+// SortedEdges returns outgoing edges of the node ordered by destination ID
+func SortedEdges(g types.Graph, n types.Node) []types.Edge {
+	edges := make([]types.Edge, 0, g.OutDegree(n))
+	g.NodeEdgeIter(n, func(e types.Edge) bool {
+		edges = append(edges, e)
+		return true
+	})
+	sort.Slice(edges, func(i, j int) bool {
+		return edges[i].Dst().ID() < edges[j].Dst().ID()
+	})
+	return edges
+}
+
+// SkipOpposite reports whether edge n -> d is left out of an undirected
+// output. A pair of opposite edges is written once, as the edge going from
+// the node with smaller ID. Edges without an opposite one are written too,
+// so reading the output back makes them go both ways.
+func SkipOpposite(g types.Graph, n, d types.Node) bool {
+	return d.ID() < n.ID() && g.HasEdgeBetween(d, n)
+}
+
+// LabelOrID returns the node label, or the ID for unlabeled nodes
+func LabelOrID(n types.Node) string {
+	if l := n.Label(); l != "" {
+		return l
+	}
+	return strconv.Itoa(n.ID())
+}
+
+// CheckNames returns an error when the ID of an unlabeled node equals the label
+// of another node. Formats naming nodes by LabelOrID would merge such nodes.
+func CheckNames(g types.Graph) error {
+	var err error
+	g.NodeIter(func(n types.Node) bool {
+		if n.Label() != "" {
+			return true
+		}
+		id := strconv.Itoa(n.ID())
+		if _, ok := g.NodeByLabel(id); ok {
+			err = fmt.Errorf("unlabeled node %s has the same name as node labeled %q", id, id)
+			return false
+		}
+		return true
+	})
+	return err
+}
@@ -0,0 +1,92 @@
+package graphutil_test
+
+import (
+	"testing"
+
+	"github.com/iimos/gorka"
+	"github.com/iimos/gorka/gralang"
+	"github.com/iimos/gorka/internal/graphutil"
+)
+
+func TestSortedEdges(t *testing.T) {
+	g := gorka.New()
+	gralang.Parse(g, "c b d; a -> d b c")
+	a, _ := g.NodeByLabel("a")
+	res := ""
+	for _, e := range graphutil.SortedEdges(g, a) {
+		res += e.Dst().Label()
+	}
+	if res != "cbd" {
+		t.Errorf("wrong order %q, expected %q", res, "cbd")
+	}
+}
+
+func TestSkipOpposite(t *testing.T) {
+	type tcase struct {
+		s    string
+		a, b string
+		skip bool
+	}
+	cases := []tcase{
+		tcase{"a -- b", "a", "b", false},
+		tcase{"a -- b", "b", "a", true},
+		tcase{"a; b -> a", "b", "a", false},
+		tcase{"a -> a", "a", "a", false},
+	}
+	for i, c := range cases {
+		g := gorka.New()
+		gralang.Parse(g, c.s)
+		a, _ := g.NodeByLabel(c.a)
+		b, _ := g.NodeByLabel(c.b)
+		if res := graphutil.SkipOpposite(g, a, b); res != c.skip {
+			t.Errorf("#%d: SkipOpposite(%s, %s) = %v, expected %v", i, c.a, c.b, res, c.skip)
+		}
+	}
+}
+
+func TestLabelOrID(t *testing.T) {
+	g := gorka.New()
+	a, _ := g.NewNode("a")
+	n, _ := g.NewNode("")
+	if graphutil.LabelOrID(a) != "a" || graphutil.LabelOrID(n) != "2" {
+		t.Errorf("wrong labels %q, %q", graphutil.LabelOrID(a), graphutil.LabelOrID(n))
+	}
+}
+
+func TestCheckNames(t *testing.T) {
+	g := gorka.New()
+	g.NewNode("")
+	g.NewNode("a")
+	if err := graphutil.CheckNames(g); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	g.NewNode("1")
+	if err := graphutil.CheckNames(g); err == nil {
+		t.Errorf("node 1 and node labeled \"1\" should collide")
+	}
+}
+
+func TestNodes(t *testing.T) {
+	g := gorka.New()
+	old, _ := g.NewNode("a")
+	ns := graphutil.NewNodes(g)
+
+	if n, err := ns.Add("1", "a"); err != nil || n != old {
+		t.Errorf("existing node is not reused: %v, %v", n, err)
+	}
+	if n, err := ns.Add("2", ""); err != nil || n.Label() != "2" {
+		t.Errorf("ID is not used as label: %v, %v", n, err)
+	}
+	if _, err := ns.Add("1", "b"); err == nil {
+		t.Errorf("no error for duplicate ID")
+	}
+	if n, ok := ns.Get("1"); !ok || n != old {
+		t.Errorf("wrong node of ID 1: %v", n)
+	}
+	if _, ok := ns.Get("3"); ok {
+		t.Errorf("unknown ID is found")
+	}
+	if g.NodesCount() != 2 || graphutil.Obtain(g, "2").ID() != 2 || graphutil.Obtain(g, "x").Label() != "x" {
+		t.Errorf("wrong graph:\n%s", g)
+	}
+}
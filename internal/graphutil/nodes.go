@@ -0,0 +1,49 @@
+package graphutil
+
+import (
+	"fmt"
+
+	"github.com/iimos/gorka/types"
+)
+
+// Obtain returns the node with the label, creating it when the graph has none
+func Obtain(g types.Graph, label string) types.Node {
+	if n, ok := g.NodeByLabel(label); ok {
+		return n
+	}
+	n, _ := g.NewNode(label)
+	return n
+}
+
+// Nodes maps node IDs used in an input file to graph nodes. Nodes are found
+// by label and created when missing, so reading into a graph that already
+// has nodes with the same labels connects to them.
+type Nodes struct {
+	g    types.Graph
+	byID map[string]types.Node
+}
+
+// NewNodes returns an empty mapping to nodes of g
+func NewNodes(g types.Graph) *Nodes {
+	return &Nodes{g: g, byID: make(map[string]types.Node)}
+}
+
+// Add maps the ID to the node with the label, empty label means the ID.
+// Every ID can be added once.
+func (ns *Nodes) Add(id, label string) (types.Node, error) {
+	if _, dup := ns.byID[id]; dup {
+		return nil, fmt.Errorf("duplicate node id %s", id)
+	}
+	if label == "" {
+		label = id
+	}
+	n := Obtain(ns.g, label)
+	ns.byID[id] = n
+	return n, nil
+}
+
+// Get returns the node the ID is mapped to
+func (ns *Nodes) Get(id string) (types.Node, bool) {
+	n, ok := ns.byID[id]
+	return n, ok
+}
@@ -0,0 +1,110 @@
+package gorka
+
+import (
+	"context"
+
+	"github.com/iimos/gorka/generic/queue"
+)
+
+// Iterator goes through the graph on demand, one node per Next call.
+// Unlike Traverse functions it can be paused, interleaved with other
+// iterators or dropped at any moment:
+//
+//	it := NewBFSIterator(g, start, Outgoing)
+//	for it.Next() {
+//		fmt.Println(it.Node(), it.Depth())
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type Iterator struct {
+	iter *iterator
+	ctx  context.Context
+	cur  step
+	err  error
+	done bool
+}
+
+// NewBFSIterator returns an iterator visiting nodes reachable from start
+// in breadth-first order, following edges in the given direction
+func NewBFSIterator(g Graph, start Node, dir Direction) *Iterator {
+	iter := newIterator(&queue.Queue[step]{}, g, start)
+	iter.dir = dir
+	return &Iterator{iter: iter}
+}
+
+// NewDFSIterator returns an iterator visiting nodes reachable from start
+// in depth-first order, following edges in the given direction
+func NewDFSIterator(g Graph, start Node, dir Direction) *Iterator {
+	iter := newIterator(&queue.Stack[step]{}, g, start)
+	iter.dir = dir
+	return &Iterator{iter: iter}
+}
+
+// Next advances to the next node. It returns false when the traversal
+// is over or failed, see Err.
+func (it *Iterator) Next() bool {
+	if it.done {
+		return false
+	}
+	var s step
+	err := it.ctxErr()
+	if err == nil {
+		s, err = it.iter.next()
+	}
+	if err != nil || s.Node == nil {
+		it.err = err
+		it.done = true
+		it.cur = step{}
+		return false
+	}
+	it.cur = s
+	return true
+}
+
+// SetContext makes Next stop the traversal once ctx is done,
+// Err returns the context error then
+func (it *Iterator) SetContext(ctx context.Context) {
+	it.ctx = ctx
+}
+
+func (it *Iterator) ctxErr() error {
+	if it.ctx == nil {
+		return nil
+	}
+	return it.ctx.Err()
+}
+
+// Node returns the current node, nil before the first Next and after the end
+func (it *Iterator) Node() Node {
+	return it.cur.Node
+}
+
+// Depth returns the number of edges between the start and the current node
+// along the traversal tree: the distance in hops for BFS and the depth of
+// the search path for DFS
+func (it *Iterator) Depth() int {
+	return it.cur.depth
+}
+
+// Parent returns the node the current node was reached from, nil for the start
+func (it *Iterator) Parent() Node {
+	return it.cur.parent
+}
+
+// Edge returns the edge the current node was reached by, nil for the start.
+// When the traversal follows incoming edges the edge goes to Parent.
+func (it *Iterator) Edge() Edge {
+	return it.cur.edge
+}
+
+// Visit returns the current node with the way it was reached
+func (it *Iterator) Visit() Visit {
+	return it.cur.visit()
+}
+
+// Err returns the error that stopped the traversal, if any,
+// such as the error of the context given to SetContext
+func (it *Iterator) Err() error {
+	return it.err
+}
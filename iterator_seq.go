@@ -0,0 +1,34 @@
+//go:build go1.23
+
+package gorka
+
+import "iter"
+
+// Nodes returns the rest of the traversal as a sequence for range loops.
+// Breaking the loop leaves the iterator usable, so the traversal can be
+// resumed with Next or another range loop.
+func (it *Iterator) Nodes() iter.Seq[Node] {
+	return func(yield func(Node) bool) {
+		for it.Next() {
+			if !yield(it.Node()) {
+				return
+			}
+		}
+	}
+}
+
+// BreadthFirst returns nodes reachable from start over outgoing edges
+// in breadth-first order. Every range loop starts a new traversal.
+func BreadthFirst(g Graph, start Node) iter.Seq[Node] {
+	return func(yield func(Node) bool) {
+		NewBFSIterator(g, start, Outgoing).Nodes()(yield)
+	}
+}
+
+// DepthFirst returns nodes reachable from start over outgoing edges
+// in depth-first order. Every range loop starts a new traversal.
+func DepthFirst(g Graph, start Node) iter.Seq[Node] {
+	return func(yield func(Node) bool) {
+		NewDFSIterator(g, start, Outgoing).Nodes()(yield)
+	}
+}
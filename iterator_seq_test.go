@@ -0,0 +1,52 @@
+//go:build go1.23
+
+package gorka
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/iimos/gorka/gralang"
+)
+
+func TestIteratorSeq(t *testing.T) {
+	g := New()
+	gralang.Parse(g, "a -> b -> c -> d")
+	a, _ := g.NodeByLabel("a")
+
+	for i := 0; i < 2; i++ {
+		res := []string{}
+		for n := range BreadthFirst(g, a) {
+			res = append(res, n.Label())
+		}
+		if s := strings.Join(res, " "); s != "a b c d" {
+			t.Errorf("#%d: wrong bfs order %s", i, s)
+		}
+	}
+
+	res := []string{}
+	for n := range DepthFirst(g, a) {
+		res = append(res, n.Label())
+		if n.Label() == "b" {
+			break
+		}
+	}
+	if s := strings.Join(res, " "); s != "a b" {
+		t.Errorf("wrong dfs order %s", s)
+	}
+
+	// the loop can be resumed
+	it := NewBFSIterator(g, a, Outgoing)
+	for n := range it.Nodes() {
+		if n.Label() == "b" {
+			break
+		}
+	}
+	res = []string{}
+	for n := range it.Nodes() {
+		res = append(res, n.Label())
+	}
+	if s := strings.Join(res, " "); s != "c d" {
+		t.Errorf("wrong resumed order %s", s)
+	}
+}
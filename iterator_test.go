@@ -0,0 +1,130 @@
+package gorka
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/iimos/gorka/gralang"
+)
+
+func TestIterator(t *testing.T) {
+	g := New()
+	gralang.Parse(g, "1 -> 11 12; 11 -> 111 112; 12 -> 121; 121 -> 1")
+	start, _ := g.NodeByLabel("1")
+
+	type visit struct {
+		depth  int
+		parent string
+	}
+	for _, bfs := range []bool{true, false} {
+		it := NewDFSIterator(g, start, Outgoing)
+		if bfs {
+			it = NewBFSIterator(g, start, Outgoing)
+		}
+		if it.Node() != nil {
+			t.Errorf("bfs=%v: node before Next", bfs)
+		}
+		visits := map[string]visit{}
+		for it.Next() {
+			l := it.Node().Label()
+			if _, ok := visits[l]; ok {
+				t.Errorf("bfs=%v: node %s visited twice", bfs, l)
+			}
+			parent := ""
+			if it.Parent() != nil {
+				parent = it.Parent().Label()
+			}
+			visits[l] = visit{it.Depth(), parent}
+		}
+		if it.Err() != nil {
+			t.Errorf("bfs=%v: error %s", bfs, it.Err())
+		}
+		if it.Next() || it.Node() != nil {
+			t.Errorf("bfs=%v: iterator continues after the end", bfs)
+		}
+
+		expected := map[string]visit{
+			"1": {0, ""}, "11": {1, "1"}, "12": {1, "1"},
+			"111": {2, "11"}, "112": {2, "11"}, "121": {2, "12"},
+		}
+		if len(visits) != len(expected) {
+			t.Errorf("bfs=%v: wrong visits %v", bfs, visits)
+		}
+		for l, v := range expected {
+			if visits[l] != v {
+				t.Errorf("bfs=%v: node %s: got %v, expected %v", bfs, l, visits[l], v)
+			}
+		}
+	}
+}
+
+func TestIteratorDepth(t *testing.T) {
+	g := New()
+	gralang.Parse(g, "a -> b; b -> c; a -> c; c -> d")
+	a, _ := g.NodeByLabel("a")
+
+	for _, it := range []*Iterator{NewBFSIterator(g, a, Outgoing), NewDFSIterator(g, a, Outgoing)} {
+		depth := map[string]int{}
+		for it.Next() {
+			n := it.Node()
+			depth[n.Label()] = it.Depth()
+			if p := it.Parent(); p != nil {
+				if !g.HasEdgeBetween(p, n) {
+					t.Errorf("%s is not a parent of %s", p, n)
+				}
+				if depth[p.Label()]+1 != it.Depth() {
+					t.Errorf("%s: wrong depth %d, parent depth is %d", n, it.Depth(), depth[p.Label()])
+				}
+			}
+		}
+	}
+
+	bfs := NewBFSIterator(g, a, Outgoing)
+	for bfs.Next() {
+		if bfs.Node().Label() == "d" && bfs.Depth() != 2 {
+			t.Errorf("wrong bfs depth of d: %d", bfs.Depth())
+		}
+	}
+}
+
+func TestIteratorInterleave(t *testing.T) {
+	g := New()
+	gralang.Parse(g, "a -> b -> c -> d")
+	a, _ := g.NodeByLabel("a")
+	d, _ := g.NodeByLabel("d")
+
+	down := NewBFSIterator(g, a, Outgoing)
+	up := NewBFSIterator(g, d, Incoming)
+	order := []string{}
+	for down.Next() && up.Next() {
+		order = append(order, down.Node().Label()+up.Node().Label())
+	}
+	if res := strings.Join(order, " "); res != "ad bc cb da" {
+		t.Errorf("wrong order %s", res)
+	}
+}
+
+func TestIteratorContext(t *testing.T) {
+	g := New()
+	gralang.Parse(g, "a -> b -> c -> d")
+	a, _ := g.NodeByLabel("a")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	it := NewBFSIterator(g, a, Outgoing)
+	it.SetContext(ctx)
+	count := 0
+	for it.Next() {
+		count++
+		if count == 2 {
+			cancel()
+		}
+	}
+	if count != 2 || it.Err() != context.Canceled {
+		t.Errorf("visited %d nodes, error %v", count, it.Err())
+	}
+	if it.Next() || it.Node() != nil {
+		t.Errorf("iterator continues after cancel")
+	}
+}
@@ -0,0 +1,270 @@
+package gorka
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/iimos/gorka/internal/graphutil"
+)
+
+// JSONEncoder writes graphs in JSON Graph Format (https://jsongraphformat.info)
+// node by node, without building the whole document in memory.
+//
+// Nodes are keyed by their IDs, labels go to "label" field and edge
+// weights to "weight" field of edge metadata:
+//
+//	{"graph":{"directed":true,"nodes":{"1":{"label":"a"},"2":{}},
+//	 "edges":[{"source":"1","target":"2","metadata":{"weight":1}}]}}
+type JSONEncoder struct {
+	w *bufio.Writer
+
+	// ID, Label and Metadata of the graph are written when not empty
+	ID       string
+	Label    string
+	Metadata map[string]interface{}
+}
+
+// NewJSONEncoder returns an encoder that writes to w
+func NewJSONEncoder(w io.Writer) *JSONEncoder {
+	return &JSONEncoder{w: bufio.NewWriter(w)}
+}
+
+// Encode writes the graph followed by a newline. JSON has no infinities
+// and NaN, graphs with such weights are reported before anything is written.
+func (enc *JSONEncoder) Encode(g Graph) error {
+	var err error
+	g.NodeIter(func(n Node) bool {
+		g.NodeEdgeIter(n, func(e Edge) bool {
+			if wt := float64(e.Wieght()); math.IsInf(wt, 0) || math.IsNaN(wt) {
+				err = fmt.Errorf("edge %d -> %d: weight %v can not be written in JSON", n.ID(), e.Dst().ID(), wt)
+			}
+			return err == nil
+		})
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+
+	w := enc.w
+	w.WriteString(`{"graph":{`)
+	if enc.ID != "" {
+		w.WriteString(`"id":`)
+		if err := writeJSON(w, enc.ID); err != nil {
+			return err
+		}
+		w.WriteByte(',')
+	}
+	if enc.Label != "" {
+		w.WriteString(`"label":`)
+		if err := writeJSON(w, enc.Label); err != nil {
+			return err
+		}
+		w.WriteByte(',')
+	}
+	w.WriteString(`"directed":true,`)
+	if len(enc.Metadata) > 0 {
+		w.WriteString(`"metadata":`)
+		if err := writeJSON(w, enc.Metadata); err != nil {
+			return err
+		}
+		w.WriteByte(',')
+	}
+
+	w.WriteString(`"nodes":{`)
+	first := true
+	g.NodeIter(func(n Node) bool {
+		if !first {
+			w.WriteByte(',')
+		}
+		first = false
+		w.WriteByte('"')
+		w.WriteString(strconv.Itoa(n.ID()))
+		w.WriteString(`":{`)
+		if l := n.Label(); l != "" {
+			w.WriteString(`"label":`)
+			if err = writeJSON(w, l); err != nil {
+				return false
+			}
+		}
+		w.WriteByte('}')
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	w.WriteString(`},"edges":[`)
+	first = true
+	g.NodeIter(func(n Node) bool {
+		for _, e := range graphutil.SortedEdges(g, n) {
+			if !first {
+				w.WriteByte(',')
+			}
+			first = false
+			w.WriteString(`{"source":"`)
+			w.WriteString(strconv.Itoa(n.ID()))
+			w.WriteString(`","target":"`)
+			w.WriteString(strconv.Itoa(e.Dst().ID()))
+			w.WriteString(`","metadata":{"weight":`)
+			if err = writeJSON(w, e.Wieght()); err != nil {
+				return false
+			}
+			w.WriteString(`}}`)
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	w.WriteString("]}}\n")
+	return w.Flush()
+}
+
+func writeJSON(w *bufio.Writer, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, the graph is written in JSON Graph Format
+func (g *graph) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	if err := NewJSONEncoder(&b).Encode(g); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(b.Bytes(), []byte{'\n'}), nil
+}
+
+type jsonDocument struct {
+	Graph  *jsonGraph  `json:"graph"`
+	Graphs []jsonGraph `json:"graphs"`
+}
+
+type jsonGraph struct {
+	Directed *bool           `json:"directed"`
+	Nodes    json.RawMessage `json:"nodes"`
+	Edges    []jsonEdge      `json:"edges"`
+}
+
+type jsonNode struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+}
+
+type jsonEdge struct {
+	Source   string `json:"source"`
+	Target   string `json:"target"`
+	Directed *bool  `json:"directed"`
+	Metadata struct {
+		Weight *float32 `json:"weight"`
+	} `json:"metadata"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It replaces the graph content
+// with a graph in JSON Graph Format. Both nodes object (version 2) and nodes
+// array (version 1) are accepted. Undirected edges are added in both directions,
+// edges without weight get weight 1.
+func (g *graph) UnmarshalJSON(data []byte) error {
+	var doc jsonDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	jg := doc.Graph
+	if jg == nil {
+		if len(doc.Graphs) != 1 {
+			return fmt.Errorf("expected one graph, got %d", len(doc.Graphs))
+		}
+		jg = &doc.Graphs[0]
+	}
+
+	var nodes []jsonNode
+	switch trimmed := bytes.TrimSpace(jg.Nodes); {
+	case len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")):
+	case trimmed[0] == '[':
+		if err := json.Unmarshal(trimmed, &nodes); err != nil {
+			return err
+		}
+	default:
+		// nodes object preserving the order of keys
+		dec := json.NewDecoder(bytes.NewReader(trimmed))
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if tok != json.Delim('{') {
+			return errors.New("nodes must be an object or an array")
+		}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			var n jsonNode
+			if err := dec.Decode(&n); err != nil {
+				return err
+			}
+			n.ID = key.(string)
+			nodes = append(nodes, n)
+		}
+	}
+
+	ng := newGraph()
+	byID := make(map[string]Node, len(nodes))
+	for _, jn := range nodes {
+		if _, exists := byID[jn.ID]; exists {
+			return fmt.Errorf("duplicate node id '%s'", jn.ID)
+		}
+		n, err := ng.NewNode(jn.Label)
+		if err != nil {
+			return err
+		}
+		byID[jn.ID] = n
+	}
+
+	directed := jg.Directed == nil || *jg.Directed
+	for _, je := range jg.Edges {
+		src, ok := byID[je.Source]
+		if !ok {
+			return fmt.Errorf("edge source '%s' is not a node", je.Source)
+		}
+		dst, ok := byID[je.Target]
+		if !ok {
+			return fmt.Errorf("edge target '%s' is not a node", je.Target)
+		}
+		w := float32(1)
+		if je.Metadata.Weight != nil {
+			w = *je.Metadata.Weight
+		}
+		if je.Directed != nil && *je.Directed || je.Directed == nil && directed {
+			ng.AddEdge(src, dst, w)
+		} else {
+			ng.AddBiEdge(src, dst, w)
+		}
+	}
+
+	g.replace(ng)
+	return nil
+}
+
+// replace moves the content of other graph into g
+func (g *graph) replace(other *graph) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.nodes = other.nodes
+	g.nodeMap = other.nodeMap
+	g.labelToNode = other.labelToNode
+	g.edgesOut = other.edgesOut
+	g.edgesIn = other.edgesIn
+	g.lastNodeID = other.lastNodeID
+}
@@ -0,0 +1,145 @@
+package gorka
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/iimos/gorka/gralang"
+)
+
+func TestJSONMarshal(t *testing.T) {
+	g := New()
+	gralang.Parse(g, "a -> b c; c -> b")
+	n, _ := g.NewNode("")
+	b, _ := g.NodeByLabel("b")
+	g.AddEdge(b, n, 2.5)
+
+	data, err := json.Marshal(g)
+	if err != nil {
+		t.Fatalf("marshal error: %s", err)
+	}
+	expected := `{"graph":{"directed":true,"nodes":{"1":{"label":"a"},"2":{"label":"b"},"3":{"label":"c"},"4":{}},` +
+		`"edges":[{"source":"1","target":"2","metadata":{"weight":1}},{"source":"1","target":"3","metadata":{"weight":1}},` +
+		`{"source":"2","target":"4","metadata":{"weight":2.5}},{"source":"3","target":"2","metadata":{"weight":1}}]}}`
+	if string(data) != expected {
+		t.Errorf("wrong json:\n%s\nexpected:\n%s", data, expected)
+	}
+
+	// graph embedded into other structs
+	wrapped, err := json.Marshal(struct{ G Graph }{g})
+	if err != nil || !strings.HasPrefix(string(wrapped), `{"G":{"graph":`) {
+		t.Errorf("wrong embedded json: %s, %v", wrapped, err)
+	}
+}
+
+func TestJSONUnmarshal(t *testing.T) {
+	type tcase struct {
+		s         string
+		nodeCount int
+		edgeCount int
+		weight    float32 // weight of a->b
+	}
+	cases := []tcase{
+		tcase{`{"graph":{"nodes":{}}}`, 0, 0, 0},
+		tcase{`{"graph":{"directed":true,"nodes":{"x":{"label":"a"},"y":{"label":"b"}},"edges":[{"source":"x","target":"y","metadata":{"weight":3}}]}}`, 2, 1, 3},
+		tcase{`{"graph":{"directed":false,"nodes":{"x":{"label":"a"},"y":{"label":"b"}},"edges":[{"source":"x","target":"y"}]}}`, 2, 2, 1},
+		tcase{`{"graph":{"directed":false,"nodes":{"x":{"label":"a"},"y":{"label":"b"}},"edges":[{"source":"x","target":"y","directed":true}]}}`, 2, 1, 1},
+		tcase{`{"graphs":[{"nodes":[{"id":"x","label":"a"},{"id":"y","label":"b"},{"id":"z"}],"edges":[{"source":"x","target":"y"},{"source":"y","target":"z"}]}]}`, 3, 2, 1},
+	}
+
+	for i, c := range cases {
+		g := New()
+		gralang.Parse(g, "old -> nodes")
+		if err := json.Unmarshal([]byte(c.s), g); err != nil {
+			t.Errorf("#%d: unmarshal error: %s", i, err)
+			continue
+		}
+		if g.NodesCount() != c.nodeCount {
+			t.Errorf("#%d: wrong nodes count - %d, expected %d", i, g.NodesCount(), c.nodeCount)
+		}
+		if g.EdgesCount() != c.edgeCount {
+			t.Errorf("#%d: wrong edges count - %d, expected %d", i, g.EdgesCount(), c.edgeCount)
+		}
+		if _, ok := g.NodeByLabel("old"); ok {
+			t.Errorf("#%d: old content is not replaced", i)
+		}
+		if c.weight != 0 {
+			a, _ := g.NodeByLabel("a")
+			g.NodeEdgeIter(a, func(e Edge) bool {
+				if e.Wieght() != c.weight {
+					t.Errorf("#%d: wrong weight %f", i, e.Wieght())
+				}
+				return true
+			})
+		}
+	}
+}
+
+func TestJSONMarshalErrors(t *testing.T) {
+	for i, wt := range []float64{math.Inf(1), math.Inf(-1), math.NaN()} {
+		g := New()
+		a, _ := g.NewNode("a")
+		b, _ := g.NewNode("b")
+		g.AddEdge(a, b, float32(wt))
+
+		var out strings.Builder
+		if err := NewJSONEncoder(&out).Encode(g); err == nil {
+			t.Errorf("#%d: no error for weight %v", i, wt)
+		}
+		if out.Len() != 0 {
+			t.Errorf("#%d: partial output %q", i, out.String())
+		}
+	}
+
+	enc := NewJSONEncoder(&strings.Builder{})
+	enc.Metadata = map[string]interface{}{"f": func() {}}
+	if err := enc.Encode(New()); err == nil {
+		t.Errorf("no error for bad metadata")
+	}
+}
+
+func TestJSONUnmarshalErrors(t *testing.T) {
+	cases := []string{
+		`[]`,
+		`{}`,
+		`{"graphs":[]}`,
+		`{"graph":{"nodes":{"x":{"label":"a"},"y":{"label":"a"}}}}`,
+		`{"graph":{"nodes":[{"id":"x"},{"id":"x"}]}}`,
+		`{"graph":{"nodes":{"x":{}},"edges":[{"source":"x","target":"y"}]}}`,
+		`{"graph":{"nodes":{"x":{}},"edges":[{"source":"y","target":"x"}]}}`,
+		`{"graph":{"nodes":{"x":1}}}`,
+		`{"graph":{"nodes":5}}`,
+		`{"graph":{"nodes":"x"}}`,
+	}
+	for i, s := range cases {
+		if err := json.Unmarshal([]byte(s), New()); err == nil {
+			t.Errorf("#%d: '%s' unmarshaled without error", i, s)
+		}
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	g, _ := NewRegular(20, 4)
+
+	var s strings.Builder
+	enc := NewJSONEncoder(&s)
+	enc.ID = "regular"
+	enc.Label = "ring"
+	enc.Metadata = map[string]interface{}{"k": 4}
+	if err := enc.Encode(g); err != nil {
+		t.Fatalf("encode error: %s", err)
+	}
+	if !strings.HasPrefix(s.String(), `{"graph":{"id":"regular","label":"ring","directed":true,"metadata":{"k":4},`) {
+		t.Errorf("wrong header: %s", s.String()[:80])
+	}
+
+	g2 := New()
+	if err := json.Unmarshal([]byte(s.String()), g2); err != nil {
+		t.Fatalf("unmarshal error: %s", err)
+	}
+	if g2.NodesCount() != g.NodesCount() || g2.EdgesCount() != g.EdgesCount() {
+		t.Errorf("wrong graph: %d nodes, %d edges", g2.NodesCount(), g2.EdgesCount())
+	}
+}
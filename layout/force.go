@@ -0,0 +1,134 @@
+package layout
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/iimos/gorka/types"
+)
+
+// ForceOptions controls the force-directed layout
+type ForceOptions struct {
+	// Iterations of the simulation, default is 300
+	Iterations int
+	// Seed of the initial random placement, the same seed gives the same layout
+	Seed int64
+	// Initial positions of nodes, nodes absent in it are placed randomly
+	Initial Layout
+}
+
+// ForceDirected lays out the graph with Fruchterman-Reingold algorithm:
+// nodes repel each other, edges pull their ends together and the moves
+// are limited by a temperature that cools down linearly. Edge directions
+// are ignored. Nodes are kept in the unit square centered at the origin.
+func ForceDirected(g types.Graph, opt *ForceOptions) Layout {
+	if opt == nil {
+		opt = &ForceOptions{}
+	}
+	iterations := opt.Iterations
+	if iterations <= 0 {
+		iterations = 300
+	}
+
+	list := nodes(g)
+	count := len(list)
+	l := make(Layout, count)
+	if count == 0 {
+		return l
+	}
+	index := make(map[int]int, count)
+	for i, n := range list {
+		index[n.ID()] = i
+	}
+
+	rnd := rand.New(rand.NewSource(opt.Seed))
+	pos := make([]Point, count)
+	for i, n := range list {
+		if p, ok := opt.Initial[n.ID()]; ok {
+			pos[i] = p
+		} else {
+			pos[i] = Point{X: rnd.Float64() - 0.5, Y: rnd.Float64() - 0.5}
+		}
+	}
+
+	// undirected adjacency without duplicates and self-loops
+	var edges [][2]int
+	for i, n := range list {
+		g.NodeEdgeIter(n, func(e types.Edge) bool {
+			j := index[e.Dst().ID()]
+			if i < j || i > j && !g.HasEdgeBetween(e.Dst(), n) {
+				edges = append(edges, [2]int{i, j})
+			}
+			return true
+		})
+	}
+	// fixed order keeps float sums and so the layout reproducible
+	sort.Slice(edges, func(a, b int) bool {
+		if edges[a][0] != edges[b][0] {
+			return edges[a][0] < edges[b][0]
+		}
+		return edges[a][1] < edges[b][1]
+	})
+
+	k := math.Sqrt(1 / float64(count)) // optimal distance for the unit area
+	disp := make([]Point, count)
+	temp := 0.1
+	cool := temp / float64(iterations+1)
+
+	for it := 0; it < iterations; it++ {
+		for i := range disp {
+			disp[i] = Point{}
+		}
+
+		// repulsion
+		for i := 0; i < count; i++ {
+			for j := i + 1; j < count; j++ {
+				dx, dy := pos[i].X-pos[j].X, pos[i].Y-pos[j].Y
+				d := math.Hypot(dx, dy)
+				if d < 1e-9 {
+					// coincident nodes are pushed apart in a random direction
+					a := rnd.Float64() * 2 * math.Pi
+					dx, dy, d = math.Cos(a)*1e-3, math.Sin(a)*1e-3, 1e-3
+				}
+				f := k * k / d
+				disp[i].X += dx / d * f
+				disp[i].Y += dy / d * f
+				disp[j].X -= dx / d * f
+				disp[j].Y -= dy / d * f
+			}
+		}
+
+		// attraction
+		for _, e := range edges {
+			i, j := e[0], e[1]
+			dx, dy := pos[i].X-pos[j].X, pos[i].Y-pos[j].Y
+			d := math.Hypot(dx, dy)
+			if d < 1e-9 {
+				continue
+			}
+			f := d * d / k
+			disp[i].X -= dx / d * f
+			disp[i].Y -= dy / d * f
+			disp[j].X += dx / d * f
+			disp[j].Y += dy / d * f
+		}
+
+		for i := range pos {
+			d := math.Hypot(disp[i].X, disp[i].Y)
+			if d > 0 {
+				step := math.Min(d, temp)
+				pos[i].X += disp[i].X / d * step
+				pos[i].Y += disp[i].Y / d * step
+			}
+			pos[i].X = math.Min(0.5, math.Max(-0.5, pos[i].X))
+			pos[i].Y = math.Min(0.5, math.Max(-0.5, pos[i].Y))
+		}
+		temp -= cool
+	}
+
+	for i, n := range list {
+		l[n.ID()] = pos[i]
+	}
+	return l
+}
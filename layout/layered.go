@@ -0,0 +1,225 @@
+package layout
+
+import (
+	"sort"
+
+	"github.com/iimos/gorka/types"
+)
+
+// LayeredOptions controls the layered layout
+type LayeredOptions struct {
+	// Sweeps of crossing reduction, default is 8
+	Sweeps int
+}
+
+// Layered lays out the graph in horizontal layers with Sugiyama method,
+// which suits DAGs: edges go downwards from layer to layer. Cycles are
+// broken by reversing edges that close them. Nodes are layered by the
+// longest path from sources, edges spanning several layers get virtual
+// nodes, and the order in layers is improved with barycenter sweeps to
+// reduce edge crossings. Layer i has Y = i, nodes of a layer are spaced
+// by 1 and centered at X = 0.
+func Layered(g types.Graph, opt *LayeredOptions) Layout {
+	if opt == nil {
+		opt = &LayeredOptions{}
+	}
+	sweeps := opt.Sweeps
+	if sweeps <= 0 {
+		sweeps = 8
+	}
+
+	list := nodes(g)
+	count := len(list)
+	index := make(map[int]int, count)
+	for i, n := range list {
+		index[n.ID()] = i
+	}
+	out := make([][]int, count)
+	for i, n := range list {
+		g.NodeEdgeIter(n, func(e types.Edge) bool {
+			if j := index[e.Dst().ID()]; j != i {
+				out[i] = append(out[i], j)
+			}
+			return true
+		})
+		sort.Ints(out[i])
+	}
+
+	dag := acyclic(out)
+	layer := longestPath(dag)
+
+	// layered graph with virtual nodes, vertices >= count are virtual
+	succ := make([][]int, count)
+	pred := make([][]int, count)
+	vlayer := append([]int(nil), layer...)
+	addVertex := func(l int) int {
+		succ = append(succ, nil)
+		pred = append(pred, nil)
+		vlayer = append(vlayer, l)
+		return len(vlayer) - 1
+	}
+	link := func(a, b int) {
+		succ[a] = append(succ[a], b)
+		pred[b] = append(pred[b], a)
+	}
+	for i := range dag {
+		for _, j := range dag[i] {
+			prev := i
+			for l := layer[i] + 1; l < layer[j]; l++ {
+				v := addVertex(l)
+				link(prev, v)
+				prev = v
+			}
+			link(prev, j)
+		}
+	}
+
+	depth := 0
+	for _, l := range vlayer {
+		if l+1 > depth {
+			depth = l + 1
+		}
+	}
+	layers := make([][]int, depth)
+	for v, l := range vlayer {
+		layers[l] = append(layers[l], v)
+	}
+
+	pos := make([]float64, len(vlayer))
+	setPositions := func(vs []int) {
+		for p, v := range vs {
+			pos[v] = float64(p)
+		}
+	}
+	for _, vs := range layers {
+		setPositions(vs)
+	}
+	barycenter := func(vs []int, adj [][]int) {
+		bc := make(map[int]float64, len(vs))
+		for _, v := range vs {
+			if len(adj[v]) == 0 {
+				bc[v] = pos[v]
+				continue
+			}
+			sum := 0.0
+			for _, u := range adj[v] {
+				sum += pos[u]
+			}
+			bc[v] = sum / float64(len(adj[v]))
+		}
+		sort.SliceStable(vs, func(a, b int) bool { return bc[vs[a]] < bc[vs[b]] })
+		setPositions(vs)
+	}
+	for s := 0; s < sweeps; s++ {
+		if s%2 == 0 {
+			for l := 1; l < depth; l++ {
+				barycenter(layers[l], pred)
+			}
+		} else {
+			for l := depth - 2; l >= 0; l-- {
+				barycenter(layers[l], succ)
+			}
+		}
+	}
+
+	res := make(Layout, count)
+	for l, vs := range layers {
+		offset := float64(len(vs)-1) / 2
+		for p, v := range vs {
+			if v < count {
+				res[list[v].ID()] = Point{X: float64(p) - offset, Y: float64(l)}
+			}
+		}
+	}
+	return res
+}
+
+// acyclic returns the graph without edges closing cycles found by DFS,
+// such edges are reversed
+func acyclic(out [][]int) [][]int {
+	const (
+		white = iota
+		grey
+		black
+	)
+	color := make([]int, len(out))
+	dag := make([][]int, len(out))
+
+	type frame struct {
+		v, next int
+	}
+	for root := range out {
+		if color[root] != white {
+			continue
+		}
+		stack := []frame{{v: root}}
+		color[root] = grey
+		for len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			if top.next == len(out[top.v]) {
+				color[top.v] = black
+				stack = stack[:len(stack)-1]
+				continue
+			}
+			u := out[top.v][top.next]
+			top.next++
+			switch color[u] {
+			case white:
+				dag[top.v] = append(dag[top.v], u)
+				color[u] = grey
+				stack = append(stack, frame{v: u})
+			case grey:
+				// back edge
+				if !contains(dag[u], top.v) {
+					dag[u] = append(dag[u], top.v)
+				}
+			default:
+				if !contains(dag[top.v], u) {
+					dag[top.v] = append(dag[top.v], u)
+				}
+			}
+		}
+	}
+	return dag
+}
+
+// longestPath assigns layers so that every edge goes at least one layer
+// down and sources are in layer 0
+func longestPath(dag [][]int) []int {
+	indeg := make([]int, len(dag))
+	for _, vs := range dag {
+		for _, v := range vs {
+			indeg[v]++
+		}
+	}
+	layer := make([]int, len(dag))
+	var queue []int
+	for v, d := range indeg {
+		if d == 0 {
+			queue = append(queue, v)
+		}
+	}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, u := range dag[v] {
+			if layer[v]+1 > layer[u] {
+				layer[u] = layer[v] + 1
+			}
+			indeg[u]--
+			if indeg[u] == 0 {
+				queue = append(queue, u)
+			}
+		}
+	}
+	return layer
+}
+
+func contains(list []int, v int) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package layout
+
+import (
+	"math"
+
+	"github.com/iimos/gorka/types"
+)
+
+// Point is a position on the plane
+type Point struct {
+	X, Y float64
+}
+
+// Layout holds node positions by node ID. Coordinates are in arbitrary
+// units, renderers scale them to fit the picture.
+type Layout map[int]Point
+
+// Bounds returns the bounding box of the layout
+func (l Layout) Bounds() (min, max Point) {
+	first := true
+	for _, p := range l {
+		if first {
+			min, max = p, p
+			first = false
+			continue
+		}
+		min.X = math.Min(min.X, p.X)
+		min.Y = math.Min(min.Y, p.Y)
+		max.X = math.Max(max.X, p.X)
+		max.Y = math.Max(max.Y, p.Y)
+	}
+	return min, max
+}
+
+// Circular places nodes evenly on the unit circle in NodeIter order,
+// starting from the top and going clockwise
+func Circular(g types.Graph) Layout {
+	count := g.NodesCount()
+	l := make(Layout, count)
+	i := 0
+	g.NodeIter(func(n types.Node) bool {
+		a := 2*math.Pi*float64(i)/float64(count) - math.Pi/2
+		l[n.ID()] = Point{X: math.Cos(a), Y: math.Sin(a)}
+		i++
+		return true
+	})
+	return l
+}
+
+// nodes returns graph nodes in NodeIter order
+func nodes(g types.Graph) []types.Node {
+	list := make([]types.Node, 0, g.NodesCount())
+	g.NodeIter(func(n types.Node) bool {
+		list = append(list, n)
+		return true
+	})
+	return list
+}
@@ -0,0 +1,146 @@
+package layout
+
+import (
+	"math"
+	"testing"
+
+	"github.com/iimos/gorka"
+	"github.com/iimos/gorka/gralang"
+	"github.com/iimos/gorka/types"
+)
+
+func parse(t *testing.T, s string) types.Graph {
+	g := gorka.New()
+	if err := gralang.Parse(g, s); err != nil {
+		t.Fatalf("parse error: %s", err)
+	}
+	return g
+}
+
+func pos(t *testing.T, g types.Graph, l Layout, label string) Point {
+	n, ok := g.NodeByLabel(label)
+	if !ok {
+		t.Fatalf("no node %s", label)
+	}
+	p, ok := l[n.ID()]
+	if !ok {
+		t.Fatalf("no position of %s", label)
+	}
+	return p
+}
+
+func near(a, b Point) bool {
+	return math.Abs(a.X-b.X) < 1e-9 && math.Abs(a.Y-b.Y) < 1e-9
+}
+
+func TestCircular(t *testing.T) {
+	g := parse(t, "a -> b -> c -> d")
+	l := Circular(g)
+	if len(l) != 4 {
+		t.Fatalf("wrong size %d", len(l))
+	}
+	expected := map[string]Point{
+		"a": {0, -1},
+		"b": {1, 0},
+		"c": {0, 1},
+		"d": {-1, 0},
+	}
+	for label, p := range expected {
+		if res := pos(t, g, l, label); !near(res, p) {
+			t.Errorf("%s: wrong position %v, expected %v", label, res, p)
+		}
+	}
+
+	min, max := l.Bounds()
+	if !near(min, Point{-1, -1}) || !near(max, Point{1, 1}) {
+		t.Errorf("wrong bounds %v %v", min, max)
+	}
+}
+
+func TestForceDirected(t *testing.T) {
+	g := parse(t, "a -> b c d; b -> c; e -> f")
+	opt := &ForceOptions{Seed: 7}
+	l := ForceDirected(g, opt)
+	if len(l) != g.NodesCount() {
+		t.Fatalf("wrong size %d", len(l))
+	}
+	for id, p := range l {
+		if math.Abs(p.X) > 0.5 || math.Abs(p.Y) > 0.5 {
+			t.Errorf("node %d is out of bounds: %v", id, p)
+		}
+	}
+
+	same := ForceDirected(g, opt)
+	for id, p := range l {
+		if same[id] != p {
+			t.Errorf("node %d: layout is not deterministic: %v != %v", id, p, same[id])
+		}
+	}
+
+	// connected nodes are closer than unconnected ones on average
+	dist := func(a, b string) float64 {
+		pa, pb := pos(t, g, l, a), pos(t, g, l, b)
+		return math.Hypot(pa.X-pb.X, pa.Y-pb.Y)
+	}
+	if dist("e", "f") >= dist("e", "a") {
+		t.Errorf("e-f %f is not closer than e-a %f", dist("e", "f"), dist("e", "a"))
+	}
+
+	fixed := ForceDirected(g, &ForceOptions{Iterations: 1, Initial: l})
+	for id, p := range l {
+		d := math.Hypot(fixed[id].X-p.X, fixed[id].Y-p.Y)
+		if d > 0.1+1e-9 {
+			t.Errorf("node %d moved too far from initial position: %f", id, d)
+		}
+	}
+
+	if res := ForceDirected(gorka.New(), nil); len(res) != 0 {
+		t.Errorf("non-empty layout of empty graph: %v", res)
+	}
+}
+
+func TestLayered(t *testing.T) {
+	type tcase struct {
+		s      string
+		layers map[string]float64
+	}
+	cases := []tcase{
+		tcase{"a -> b -> c", map[string]float64{"a": 0, "b": 1, "c": 2}},
+		tcase{"a -> b c; b -> d; c -> d; a -> d", map[string]float64{"a": 0, "b": 1, "c": 1, "d": 2}},
+		tcase{"a -> b -> c -> a", map[string]float64{"a": 0, "b": 1, "c": 2}},
+		tcase{"a -> b; c", map[string]float64{"a": 0, "b": 1, "c": 0}},
+	}
+
+	for i, c := range cases {
+		g := parse(t, c.s)
+		l := Layered(g, nil)
+		if len(l) != g.NodesCount() {
+			t.Errorf("#%d: wrong size %d", i, len(l))
+			continue
+		}
+		for label, y := range c.layers {
+			if p := pos(t, g, l, label); p.Y != y {
+				t.Errorf("#%d: %s: wrong layer %v, expected %v", i, label, p.Y, y)
+			}
+		}
+		// nodes of a layer have distinct positions
+		seen := make(map[Point]bool)
+		for id, p := range l {
+			if seen[p] {
+				t.Errorf("#%d: node %d overlaps another node at %v", i, id, p)
+			}
+			seen[p] = true
+		}
+	}
+}
+
+func TestLayeredCrossings(t *testing.T) {
+	// without reordering a1-b2 and a2-b1 cross
+	g := parse(t, "a1 a2; b2 b1; a1 -> b1; a2 -> b2")
+	l := Layered(g, nil)
+	a1, a2 := pos(t, g, l, "a1"), pos(t, g, l, "a2")
+	b1, b2 := pos(t, g, l, "b1"), pos(t, g, l, "b2")
+	if (a1.X < a2.X) != (b1.X < b2.X) {
+		t.Errorf("edges cross: a1 %v a2 %v b1 %v b2 %v", a1, a2, b1, b2)
+	}
+}
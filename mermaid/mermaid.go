@@ -0,0 +1,131 @@
+package mermaid
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/iimos/gorka/internal/graphutil"
+	"github.com/iimos/gorka/types"
+)
+
+// Options controls how a graph is written
+type Options struct {
+	// Direction of the flowchart: TB, TD, BT, RL or LR, default is TD
+	Direction string
+	// Undirected writes `---` links, see graphutil.SkipOpposite
+	Undirected bool
+	// Weights writes edge weights as link labels
+	Weights bool
+
+	// Nodes selects nodes to write, edges are written when both ends are
+	// selected. Nil means all nodes.
+	Nodes func(n types.Node) bool
+	// NodeLabel returns text of the node, nil means node labels,
+	// unlabeled nodes are written as their IDs
+	NodeLabel func(n types.Node) string
+	// EdgeLabel returns text of the link, it takes precedence over Weights
+	EdgeLabel func(e types.Edge) string
+}
+
+// Write writes the graph as Mermaid flowchart. Nodes get ids `n<ID>`
+// and their labels as text.
+func Write(w io.Writer, g types.Graph, opt *Options) error {
+	if opt == nil {
+		opt = &Options{}
+	}
+	dir := opt.Direction
+	if dir == "" {
+		dir = "TD"
+	}
+	switch dir {
+	case "TB", "TD", "BT", "RL", "LR":
+	default:
+		return fmt.Errorf("unknown direction '%s'", dir)
+	}
+	selected := func(n types.Node) bool {
+		return opt.Nodes == nil || opt.Nodes(n)
+	}
+
+	b := bufio.NewWriter(w)
+	b.WriteString("flowchart ")
+	b.WriteString(dir)
+	b.WriteByte('\n')
+
+	g.NodeIter(func(n types.Node) bool {
+		if !selected(n) {
+			return true
+		}
+		label := graphutil.LabelOrID(n)
+		if opt.NodeLabel != nil {
+			label = opt.NodeLabel(n)
+		}
+		b.WriteString("    ")
+		b.WriteString(nodeID(n))
+		b.WriteString(`["`)
+		b.WriteString(escape(label))
+		b.WriteString("\"]\n")
+		return true
+	})
+
+	link := "-->"
+	if opt.Undirected {
+		link = "---"
+	}
+	g.NodeIter(func(n types.Node) bool {
+		if !selected(n) {
+			return true
+		}
+		for _, e := range graphutil.SortedEdges(g, n) {
+			d := e.Dst()
+			if !selected(d) {
+				continue
+			}
+			if opt.Undirected && graphutil.SkipOpposite(g, n, d) {
+				continue
+			}
+
+			text := ""
+			if opt.EdgeLabel != nil {
+				text = opt.EdgeLabel(e)
+			} else if opt.Weights {
+				text = strconv.FormatFloat(float64(e.Wieght()), 'g', -1, 32)
+			}
+
+			b.WriteString("    ")
+			b.WriteString(nodeID(n))
+			b.WriteByte(' ')
+			b.WriteString(link)
+			if text != "" {
+				b.WriteString(`|"`)
+				b.WriteString(escape(text))
+				b.WriteString(`"|`)
+			}
+			b.WriteByte(' ')
+			b.WriteString(nodeID(d))
+			b.WriteByte('\n')
+		}
+		return true
+	})
+	return b.Flush()
+}
+
+// String returns the graph as Mermaid flowchart
+func String(g types.Graph, opt *Options) string {
+	var s strings.Builder
+	Write(&s, g, opt)
+	return s.String()
+}
+
+var escaper = strings.NewReplacer(`"`, "#quot;", "\r", "", "\n", "<br>")
+
+// escape makes text safe to put in double quotes
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+func nodeID(n types.Node) string {
+	return "n" + strconv.Itoa(n.ID())
+}
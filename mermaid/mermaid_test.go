@@ -0,0 +1,72 @@
+package mermaid
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/iimos/gorka"
+	"github.com/iimos/gorka/gralang"
+	"github.com/iimos/gorka/types"
+)
+
+func TestWrite(t *testing.T) {
+	type tcase struct {
+		s   string
+		opt *Options
+		out string
+	}
+	cases := []tcase{
+		tcase{"", nil, "flowchart TD\n"},
+		tcase{"a -> b c", nil,
+			"flowchart TD\n    n1[\"a\"]\n    n2[\"b\"]\n    n3[\"c\"]\n    n1 --> n2\n    n1 --> n3\n"},
+		tcase{"a -- b; b -> c", &Options{Direction: "LR", Undirected: true},
+			"flowchart LR\n    n1[\"a\"]\n    n2[\"b\"]\n    n3[\"c\"]\n    n1 --- n2\n    n2 --- n3\n"},
+		tcase{"a -> b", &Options{Weights: true},
+			"flowchart TD\n    n1[\"a\"]\n    n2[\"b\"]\n    n1 -->|\"1\"| n2\n"},
+		tcase{"\"say\" -> b", nil,
+			"flowchart TD\n    n1[\"#quot;say#quot;\"]\n    n2[\"b\"]\n    n1 --> n2\n"},
+		tcase{"a -> b -> c -> a", &Options{Nodes: func(n types.Node) bool { return n.Label() != "b" }},
+			"flowchart TD\n    n1[\"a\"]\n    n3[\"c\"]\n    n3 --> n1\n"},
+	}
+
+	for i, c := range cases {
+		g := gorka.New()
+		if err := gralang.Parse(g, c.s); err != nil {
+			t.Errorf("#%d: parse error: %s", i, err)
+			continue
+		}
+		res := String(g, c.opt)
+		if res != c.out {
+			t.Errorf("#%d: wrong output:\n%s\nexpected:\n%s", i, res, c.out)
+		}
+	}
+}
+
+func TestWriteLabels(t *testing.T) {
+	g := gorka.New()
+	a, _ := g.NewNode("a")
+	b, _ := g.NewNode("")
+	g.AddEdge(a, b, 2.5)
+
+	opt := &Options{
+		Direction: "BT",
+		Weights:   true,
+		NodeLabel: func(n types.Node) string {
+			return strings.ToUpper(n.String()) + "\nnode"
+		},
+		EdgeLabel: func(e types.Edge) string {
+			return "uses"
+		},
+	}
+	res := String(g, opt)
+	if !strings.Contains(res, "n1 -->|\"uses\"| n2") {
+		t.Errorf("edge label is not written:\n%s", res)
+	}
+	if !strings.Contains(res, "<br>node\"]") {
+		t.Errorf("new line in node label is not escaped:\n%s", res)
+	}
+
+	if err := Write(&strings.Builder{}, g, &Options{Direction: "up"}); err == nil {
+		t.Errorf("expected error on unknown direction")
+	}
+}
@@ -0,0 +1,311 @@
+package mtx
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iimos/gorka/internal/graphutil"
+	"github.com/iimos/gorka/types"
+)
+
+// Header is the banner and size line of a Matrix Market file
+type Header struct {
+	Field    string // real, integer or pattern
+	Symmetry string // general, symmetric or skew-symmetric
+	Rows     int
+	Cols     int
+	Entries  int
+}
+
+// Options controls how matrices map to graphs
+type Options struct {
+	// Bipartite reads rows and columns as distinct nodes labeled "r<i>" and
+	// "c<j>", which allows rectangular matrices. Otherwise the matrix must be
+	// square and row i and column i are the same node labeled "<i>".
+	Bipartite bool
+	// Pattern writes only positions of entries, without weights
+	Pattern bool
+	// Symmetric writes the lower triangle of a symmetric adjacency matrix,
+	// the graph must have an opposite edge of equal weight for every edge
+	Symmetric bool
+}
+
+const banner = "%%MatrixMarket"
+
+// maxNodes limits the graph size declared by the size line, so that a short
+// file can't make Read create nodes until memory runs out
+const maxNodes = 1 << 26
+
+// Read reads a sparse matrix in Matrix Market coordinate format as an
+// adjacency matrix: entry (i, j) with value v becomes edge i -> j of weight v.
+// Pattern matrices get weight 1, symmetric matrices get both directions.
+// Matrices of more than 1<<26 nodes are rejected.
+func Read(g types.Graph, r io.Reader, opt *Options) (*Header, error) {
+	if g == nil {
+		return nil, errors.New("graph is empty")
+	}
+	if opt == nil {
+		opt = &Options{}
+	}
+
+	scan := bufio.NewScanner(r)
+	scan.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	errorf := func(format string, args ...interface{}) error {
+		return fmt.Errorf("line %d: %s", line, fmt.Sprintf(format, args...))
+	}
+
+	if !scan.Scan() {
+		if err := scan.Err(); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("banner is missing")
+	}
+	line++
+	h, err := parseBanner(scan.Text())
+	if err != nil {
+		return nil, errorf("%s", err)
+	}
+
+	// size line follows comments
+	var fields []string
+	for {
+		if !scan.Scan() {
+			if err := scan.Err(); err != nil {
+				return nil, err
+			}
+			return nil, errors.New("size line is missing")
+		}
+		line++
+		fields = strings.Fields(scan.Text())
+		if len(fields) > 0 && !strings.HasPrefix(fields[0], "%") {
+			break
+		}
+	}
+	if len(fields) != 3 {
+		return nil, errorf("expected 'rows columns entries'")
+	}
+	sizes := make([]int, 3)
+	for i, f := range fields {
+		if sizes[i], err = strconv.Atoi(f); err != nil || sizes[i] < 0 {
+			return nil, errorf("wrong size '%s'", f)
+		}
+	}
+	h.Rows, h.Cols, h.Entries = sizes[0], sizes[1], sizes[2]
+	if !opt.Bipartite && h.Rows != h.Cols {
+		return nil, errorf("matrix %dx%d is not square", h.Rows, h.Cols)
+	}
+	if h.Symmetry != "general" && h.Rows != h.Cols {
+		return nil, errorf("%s matrix is not square", h.Symmetry)
+	}
+	if h.Rows > maxNodes || h.Cols > maxNodes || opt.Bipartite && h.Rows+h.Cols > maxNodes {
+		return nil, errorf("matrix %dx%d is too large, the limit is %d nodes", h.Rows, h.Cols, maxNodes)
+	}
+
+	rows, cols := nodes(g, h, opt)
+
+	count := 0
+	for scan.Scan() {
+		line++
+		fields := strings.Fields(scan.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "%") {
+			continue
+		}
+		want := 3
+		if h.Field == "pattern" {
+			want = 2
+		}
+		if len(fields) != want {
+			return nil, errorf("expected %d fields, got %d", want, len(fields))
+		}
+		i, err := strconv.Atoi(fields[0])
+		if err != nil || i < 1 || i > h.Rows {
+			return nil, errorf("wrong row index '%s'", fields[0])
+		}
+		j, err := strconv.Atoi(fields[1])
+		if err != nil || j < 1 || j > h.Cols {
+			return nil, errorf("wrong column index '%s'", fields[1])
+		}
+		w := float32(1)
+		if want == 3 {
+			f, err := strconv.ParseFloat(fields[2], 32)
+			if err != nil || h.Field == "integer" && f != math.Trunc(f) {
+				return nil, errorf("wrong %s value '%s'", h.Field, fields[2])
+			}
+			w = float32(f)
+		}
+
+		count++
+		if count > h.Entries {
+			return nil, errorf("more than %d entries", h.Entries)
+		}
+		g.AddEdge(rows[i-1], cols[j-1], w)
+		if i != j {
+			switch h.Symmetry {
+			case "symmetric":
+				g.AddEdge(cols[j-1], rows[i-1], w)
+			case "skew-symmetric":
+				g.AddEdge(cols[j-1], rows[i-1], -w)
+			}
+		}
+	}
+	if err := scan.Err(); err != nil {
+		return nil, err
+	}
+	if count != h.Entries {
+		return nil, fmt.Errorf("expected %d entries, got %d", h.Entries, count)
+	}
+	return h, nil
+}
+
+func parseBanner(s string) (*Header, error) {
+	fields := strings.Fields(strings.ToLower(s))
+	if len(fields) != 5 || fields[0] != strings.ToLower(banner) {
+		return nil, errors.New("not a Matrix Market file")
+	}
+	if fields[1] != "matrix" {
+		return nil, fmt.Errorf("unsupported object '%s'", fields[1])
+	}
+	if fields[2] != "coordinate" {
+		return nil, fmt.Errorf("unsupported format '%s'", fields[2])
+	}
+	h := &Header{Field: fields[3], Symmetry: fields[4]}
+	switch h.Field {
+	case "real", "double", "integer", "pattern":
+	default:
+		return nil, fmt.Errorf("unsupported field '%s'", h.Field)
+	}
+	if h.Field == "double" {
+		h.Field = "real"
+	}
+	switch h.Symmetry {
+	case "general", "symmetric", "skew-symmetric":
+	default:
+		return nil, fmt.Errorf("unsupported symmetry '%s'", h.Symmetry)
+	}
+	return h, nil
+}
+
+// nodes returns nodes of matrix rows and columns
+func nodes(g types.Graph, h *Header, opt *Options) (rows, cols []types.Node) {
+	if !opt.Bipartite {
+		rows = make([]types.Node, h.Rows)
+		for i := range rows {
+			rows[i] = graphutil.Obtain(g, strconv.Itoa(i+1))
+		}
+		return rows, rows
+	}
+	rows = make([]types.Node, h.Rows)
+	for i := range rows {
+		rows[i] = graphutil.Obtain(g, "r"+strconv.Itoa(i+1))
+	}
+	cols = make([]types.Node, h.Cols)
+	for j := range cols {
+		cols[j] = graphutil.Obtain(g, "c"+strconv.Itoa(j+1))
+	}
+	return rows, cols
+}
+
+// ReadFile reads a Matrix Market file
+func ReadFile(g types.Graph, name string, opt *Options) (*Header, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h, err := Read(g, f, opt)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", name, err)
+	}
+	return h, nil
+}
+
+// Write writes the adjacency matrix of the graph in Matrix Market coordinate
+// format. Nodes are numbered from 1 in NodeIter order. Options.Bipartite is ignored.
+func Write(w io.Writer, g types.Graph, opt *Options) error {
+	if opt == nil {
+		opt = &Options{}
+	}
+
+	index := make([]int, g.MaxNodeID()+1)
+	count := 0
+	g.NodeIter(func(n types.Node) bool {
+		count++
+		index[n.ID()] = count
+		return true
+	})
+
+	type entry struct {
+		i, j int
+		w    float32
+	}
+	var entries []entry
+	var err error
+	g.NodeIter(func(n types.Node) bool {
+		g.NodeEdgeIter(n, func(e types.Edge) bool {
+			i, j := index[n.ID()], index[e.Dst().ID()]
+			if opt.Symmetric {
+				if !hasEdge(g, e.Dst(), n, e.Wieght()) {
+					err = fmt.Errorf("graph is not symmetric: edge %s has no opposite", e)
+					return false
+				}
+				if i < j {
+					return true
+				}
+			}
+			entries = append(entries, entry{i: i, j: j, w: e.Wieght()})
+			return true
+		})
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	// column-major order is conventional
+	sort.Slice(entries, func(a, b int) bool {
+		if entries[a].j != entries[b].j {
+			return entries[a].j < entries[b].j
+		}
+		return entries[a].i < entries[b].i
+	})
+
+	field, symmetry := "real", "general"
+	if opt.Pattern {
+		field = "pattern"
+	}
+	if opt.Symmetric {
+		symmetry = "symmetric"
+	}
+
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, "%s matrix coordinate %s %s\n", banner, field, symmetry)
+	fmt.Fprintf(b, "%d %d %d\n", count, count, len(entries))
+	for _, e := range entries {
+		b.WriteString(strconv.Itoa(e.i))
+		b.WriteByte(' ')
+		b.WriteString(strconv.Itoa(e.j))
+		if !opt.Pattern {
+			b.WriteByte(' ')
+			b.WriteString(strconv.FormatFloat(float64(e.w), 'g', -1, 32))
+		}
+		b.WriteByte('\n')
+	}
+	return b.Flush()
+}
+
+// hasEdge reports whether the graph has edge a -> b of weight w
+func hasEdge(g types.Graph, a, b types.Node, w float32) bool {
+	found := false
+	g.NodeEdgeIter(a, func(e types.Edge) bool {
+		found = e.Dst().ID() == b.ID() && e.Wieght() == w
+		return !found
+	})
+	return found
+}
@@ -0,0 +1,158 @@
+package mtx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/iimos/gorka"
+	"github.com/iimos/gorka/internal/graphtest"
+)
+
+func TestRead(t *testing.T) {
+	type e struct {
+		a, b string
+		w    float32
+	}
+	type tcase struct {
+		text      string
+		opt       *Options
+		header    Header
+		nodeCount int
+		edgeCount int
+		edges     []e
+	}
+	cases := []tcase{
+		tcase{"%%MatrixMarket matrix coordinate real general\n% comment\n%\n3 3 3\n1 2 0.5\n2 3 -2\n3 1 1e2\n", nil,
+			Header{"real", "general", 3, 3, 3}, 3, 3,
+			[]e{{"1", "2", 0.5}, {"2", "3", -2}, {"3", "1", 100}}},
+		tcase{"%%MatrixMarket matrix coordinate pattern symmetric\n4 4 3\n2 1\n3 1\n4 4\n", nil,
+			Header{"pattern", "symmetric", 4, 4, 3}, 4, 5,
+			[]e{{"1", "2", 1}, {"2", "1", 1}, {"1", "3", 1}, {"4", "4", 1}}},
+		tcase{"%%MatrixMarket matrix coordinate integer skew-symmetric\n2 2 1\n2 1 3\n", nil,
+			Header{"integer", "skew-symmetric", 2, 2, 1}, 2, 2,
+			[]e{{"2", "1", 3}, {"1", "2", -3}}},
+		tcase{"%%matrixmarket MATRIX Coordinate Double General\n2 3 2\n1 3 1\n2 1 2\n", &Options{Bipartite: true},
+			Header{"real", "general", 2, 3, 2}, 5, 2,
+			[]e{{"r1", "c3", 1}, {"r2", "c1", 2}}},
+	}
+	for i, c := range cases {
+		g := gorka.New()
+		h, err := Read(g, strings.NewReader(c.text), c.opt)
+		if err != nil {
+			t.Errorf("#%d: %s", i, err)
+			continue
+		}
+		if *h != c.header {
+			t.Errorf("#%d: expected header %+v, got %+v", i, c.header, *h)
+		}
+		if g.NodesCount() != c.nodeCount {
+			t.Errorf("#%d: expected %d nodes, got %d", i, c.nodeCount, g.NodesCount())
+		}
+		if g.EdgesCount() != c.edgeCount {
+			t.Errorf("#%d: expected %d edges, got %d", i, c.edgeCount, g.EdgesCount())
+		}
+		for _, edge := range c.edges {
+			if w, ok := graphtest.Weight(g, edge.a, edge.b); !ok || w != edge.w {
+				t.Errorf("#%d: expected edge %s -> %s of weight %v", i, edge.a, edge.b, edge.w)
+			}
+		}
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	cases := []string{
+		"",
+		"%%MatrixMarket matrix array real general\n2 2\n1\n2\n3\n4\n",
+		"%%MatrixMarket matrix coordinate complex general\n1 1 1\n1 1 1 0\n",
+		"%%MatrixMarket matrix coordinate real hermitian\n1 1 0\n",
+		"%%MatrixMarket vector coordinate real general\n1 1 0\n",
+		"% not a banner\n1 1 0\n",
+		"%%MatrixMarket matrix coordinate real general\n",
+		"%%MatrixMarket matrix coordinate real general\n2 3 0\n",
+		"%%MatrixMarket matrix coordinate real general\n2 2\n",
+		"%%MatrixMarket matrix coordinate real general\n2 2 1\n3 1 1\n",
+		"%%MatrixMarket matrix coordinate real general\n2 2 1\n1 0 1\n",
+		"%%MatrixMarket matrix coordinate real general\n2 2 1\n1 1\n",
+		"%%MatrixMarket matrix coordinate real general\n2 2 1\n1 1 x\n",
+		"%%MatrixMarket matrix coordinate integer general\n2 2 1\n1 1 1.5\n",
+		"%%MatrixMarket matrix coordinate real general\n2 2 2\n1 1 1\n",
+		"%%MatrixMarket matrix coordinate real general\n2 2 1\n1 1 1\n2 2 1\n",
+		"%%MatrixMarket matrix coordinate pattern general\n2000000000 2000000000 0\n",
+		"%%MatrixMarket matrix coordinate real symmetric\n2 3 0\n",
+	}
+	for i, c := range cases {
+		if _, err := Read(gorka.New(), strings.NewReader(c), &Options{Bipartite: i == len(cases)-1}); err == nil {
+			t.Errorf("#%d: expected error", i)
+		}
+	}
+	huge := "%%MatrixMarket matrix coordinate pattern general\n40000000 40000000 0\n"
+	if _, err := Read(gorka.New(), strings.NewReader(huge), &Options{Bipartite: true}); err == nil {
+		t.Errorf("expected error on too many rows and columns")
+	}
+	if _, err := Read(nil, strings.NewReader(cases[0]), nil); err == nil {
+		t.Errorf("expected error on nil graph")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	g := gorka.New()
+	a, _ := g.NewNode("a")
+	b, _ := g.NewNode("b")
+	c, _ := g.NewNode("c")
+	g.AddEdge(a, b, 2)
+	g.AddEdge(c, a, 0.5)
+	g.AddEdge(b, b, 1)
+
+	var tests = []struct {
+		opt  *Options
+		want string
+	}{
+		{nil, "%%MatrixMarket matrix coordinate real general\n3 3 3\n3 1 0.5\n1 2 2\n2 2 1\n"},
+		{&Options{Pattern: true}, "%%MatrixMarket matrix coordinate pattern general\n3 3 3\n3 1\n1 2\n2 2\n"},
+	}
+	for i, test := range tests {
+		var buf bytes.Buffer
+		if err := Write(&buf, g, test.opt); err != nil {
+			t.Errorf("#%d: %s", i, err)
+			continue
+		}
+		if buf.String() != test.want {
+			t.Errorf("#%d: expected\n%s\ngot\n%s", i, test.want, buf.String())
+		}
+	}
+
+	if err := Write(&bytes.Buffer{}, g, &Options{Symmetric: true}); err == nil {
+		t.Errorf("expected error on asymmetric graph")
+	}
+}
+
+func TestWriteSymmetric(t *testing.T) {
+	g := gorka.New()
+	a, _ := g.NewNode("")
+	b, _ := g.NewNode("")
+	c, _ := g.NewNode("")
+	g.AddBiEdge(a, b, 2)
+	g.AddBiEdge(b, c, 3)
+	g.AddEdge(c, c, 1)
+
+	var buf bytes.Buffer
+	if err := Write(&buf, g, &Options{Symmetric: true}); err != nil {
+		t.Fatal(err)
+	}
+	want := "%%MatrixMarket matrix coordinate real symmetric\n3 3 3\n2 1 2\n3 2 3\n3 3 1\n"
+	if buf.String() != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, buf.String())
+	}
+
+	g2 := gorka.New()
+	if _, err := Read(g2, &buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if g2.EdgesCount() != g.EdgesCount() {
+		t.Errorf("expected %d edges after round trip, got %d", g.EdgesCount(), g2.EdgesCount())
+	}
+	if w, ok := graphtest.Weight(g2, "3", "2"); !ok || w != 3 {
+		t.Errorf("expected edge 3 -> 2 of weight 3")
+	}
+}
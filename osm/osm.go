@@ -0,0 +1,276 @@
+package osm
+
+import (
+	"compress/bzip2"
+	"compress/gzip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/iimos/gorka/internal/graphutil"
+	"github.com/iimos/gorka/types"
+)
+
+// Point is a geographic position in degrees
+type Point struct {
+	Lat, Lon float64
+}
+
+// Coords holds positions of graph nodes by node ID
+type Coords map[int]Point
+
+// DefaultHighways are highway types routable by car
+var DefaultHighways = []string{
+	"motorway", "motorway_link", "trunk", "trunk_link",
+	"primary", "primary_link", "secondary", "secondary_link",
+	"tertiary", "tertiary_link", "unclassified", "residential",
+	"living_street", "service", "road",
+}
+
+// Options controls which ways are imported
+type Options struct {
+	// Highways lists accepted values of the highway tag,
+	// empty means DefaultHighways and "*" accepts any highway
+	Highways []string
+	// IgnoreOneway adds every way in both directions
+	IgnoreOneway bool
+}
+
+const earthRadius = 6371008.8 // meters
+
+// Distance returns great-circle distance between points in meters
+func Distance(a, b Point) float64 {
+	const rad = math.Pi / 180
+	dlat := (b.Lat - a.Lat) * rad
+	dlon := (b.Lon - a.Lon) * rad
+	h := math.Sin(dlat/2)*math.Sin(dlat/2) +
+		math.Cos(a.Lat*rad)*math.Cos(b.Lat*rad)*math.Sin(dlon/2)*math.Sin(dlon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(math.Min(1, h)))
+}
+
+// Read reads OSM XML and adds the road network to the graph. Every OSM node
+// used by an accepted way becomes a graph node labeled with the OSM node id,
+// every pair of consecutive way nodes becomes an edge weighted by its length
+// in meters. One-way roads get edges in the allowed direction only.
+// Returns positions of the added nodes.
+func Read(g types.Graph, r io.Reader, opt *Options) (Coords, error) {
+	if g == nil {
+		return nil, errors.New("graph is empty")
+	}
+	if opt == nil {
+		opt = &Options{}
+	}
+	highways := opt.Highways
+	if len(highways) == 0 {
+		highways = DefaultHighways
+	}
+	accepted := make(map[string]bool, len(highways))
+	for _, h := range highways {
+		accepted[h] = true
+	}
+
+	im := importer{
+		g:        g,
+		opt:      opt,
+		accepted: accepted,
+		points:   make(map[int64]Point),
+		nodes:    make(map[int64]types.Node),
+		coords:   make(Coords),
+	}
+
+	dec := xml.NewDecoder(r)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return im.coords, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		se, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch se.Name.Local {
+		case "node":
+			if err := im.node(se); err != nil {
+				return nil, err
+			}
+		case "way":
+			w, err := readWay(dec, se)
+			if err != nil {
+				return nil, err
+			}
+			im.way(w)
+		}
+	}
+}
+
+// ReadFile reads OSM XML from the named file, .gz and .bz2 files are decompressed
+func ReadFile(g types.Graph, name string, opt *Options) (Coords, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	switch {
+	case strings.HasSuffix(name, ".gz"):
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err)
+		}
+		r = zr
+	case strings.HasSuffix(name, ".bz2"):
+		r = bzip2.NewReader(f)
+	}
+
+	coords, err := Read(g, r, opt)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", name, err)
+	}
+	return coords, nil
+}
+
+type importer struct {
+	g        types.Graph
+	opt      *Options
+	accepted map[string]bool
+	points   map[int64]Point      // all OSM nodes
+	nodes    map[int64]types.Node // OSM nodes added to the graph
+	coords   Coords
+}
+
+type way struct {
+	id   int64
+	refs []int64
+	tags map[string]string
+}
+
+func (im *importer) node(se xml.StartElement) error {
+	var id int64
+	var p Point
+	var err error
+	for _, a := range se.Attr {
+		switch a.Name.Local {
+		case "id":
+			id, err = strconv.ParseInt(a.Value, 10, 64)
+		case "lat":
+			p.Lat, err = strconv.ParseFloat(a.Value, 64)
+		case "lon":
+			p.Lon, err = strconv.ParseFloat(a.Value, 64)
+		}
+		if err != nil {
+			return fmt.Errorf("node %s: wrong %s: %s", attr(se, "id"), a.Name.Local, a.Value)
+		}
+	}
+	im.points[id] = p
+	return nil
+}
+
+func readWay(dec *xml.Decoder, se xml.StartElement) (*way, error) {
+	id, err := strconv.ParseInt(attr(se, "id"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("way: wrong id '%s'", attr(se, "id"))
+	}
+	w := &way{id: id, tags: make(map[string]string)}
+
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "nd":
+				ref, err := strconv.ParseInt(attr(t, "ref"), 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("way %d: wrong node ref '%s'", id, attr(t, "ref"))
+				}
+				w.refs = append(w.refs, ref)
+			case "tag":
+				w.tags[attr(t, "k")] = attr(t, "v")
+			}
+		case xml.EndElement:
+			if t.Name.Local == "way" {
+				return w, nil
+			}
+		}
+	}
+}
+
+// direction returns 1 for one-way roads, -1 for one-way roads drawn against
+// the traffic and 0 for two-way roads
+func (im *importer) direction(w *way) int {
+	if im.opt.IgnoreOneway {
+		return 0
+	}
+	switch w.tags["oneway"] {
+	case "yes", "true", "1":
+		return 1
+	case "-1", "reverse":
+		return -1
+	case "no", "false", "0":
+		return 0
+	}
+	if w.tags["junction"] == "roundabout" || w.tags["highway"] == "motorway" {
+		return 1
+	}
+	return 0
+}
+
+func (im *importer) way(w *way) {
+	hw, ok := w.tags["highway"]
+	if !ok || !(im.accepted[hw] || im.accepted["*"]) || w.tags["area"] == "yes" {
+		return
+	}
+	dir := im.direction(w)
+
+	for i := 1; i < len(w.refs); i++ {
+		a, aok := im.points[w.refs[i-1]]
+		b, bok := im.points[w.refs[i]]
+		if !aok || !bok || w.refs[i-1] == w.refs[i] {
+			// node is out of the extract
+			continue
+		}
+		na, nb := im.graphNode(w.refs[i-1], a), im.graphNode(w.refs[i], b)
+		length := float32(Distance(a, b))
+		switch dir {
+		case 1:
+			im.g.AddEdge(na, nb, length)
+		case -1:
+			im.g.AddEdge(nb, na, length)
+		default:
+			im.g.AddBiEdge(na, nb, length)
+		}
+	}
+}
+
+func (im *importer) graphNode(id int64, p Point) types.Node {
+	if n, ok := im.nodes[id]; ok {
+		return n
+	}
+	n := graphutil.Obtain(im.g, strconv.FormatInt(id, 10))
+	im.nodes[id] = n
+	im.coords[n.ID()] = p
+	return n
+}
+
+func attr(se xml.StartElement, name string) string {
+	for _, a := range se.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,234 @@
+package osm
+
+import (
+	"compress/gzip"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iimos/gorka"
+	"github.com/iimos/gorka/types"
+)
+
+const sample = `<?xml version="1.0" encoding="UTF-8"?>
+<osm version="0.6">
+ <bounds minlat="55.75" minlon="37.61" maxlat="55.76" maxlon="37.62"/>
+ <node id="1" lat="55.7500" lon="37.6100"/>
+ <node id="2" lat="55.7510" lon="37.6100"/>
+ <node id="3" lat="55.7510" lon="37.6120"/>
+ <node id="4" lat="55.7520" lon="37.6120">
+  <tag k="highway" v="traffic_signals"/>
+ </node>
+ <node id="5" lat="55.7530" lon="37.6120"/>
+ <node id="6" lat="55.7540" lon="37.6120"/>
+ <way id="100">
+  <nd ref="1"/>
+  <nd ref="2"/>
+  <nd ref="3"/>
+  <tag k="highway" v="residential"/>
+ </way>
+ <way id="101">
+  <nd ref="3"/>
+  <nd ref="4"/>
+  <tag k="highway" v="primary"/>
+  <tag k="oneway" v="yes"/>
+ </way>
+ <way id="102">
+  <nd ref="5"/>
+  <nd ref="4"/>
+  <tag k="highway" v="secondary"/>
+  <tag k="oneway" v="-1"/>
+ </way>
+ <way id="103">
+  <nd ref="5"/>
+  <nd ref="6"/>
+  <tag k="highway" v="footway"/>
+ </way>
+ <way id="104">
+  <nd ref="1"/>
+  <nd ref="6"/>
+  <tag k="building" v="yes"/>
+ </way>
+ <way id="105">
+  <nd ref="6"/>
+  <nd ref="99"/>
+  <tag k="highway" v="residential"/>
+ </way>
+ <relation id="200">
+  <member type="way" ref="100" role=""/>
+ </relation>
+</osm>`
+
+func TestDistance(t *testing.T) {
+	var tests = []struct {
+		a, b Point
+		want float64 // meters
+	}{
+		{Point{0, 0}, Point{0, 0}, 0},
+		{Point{0, 0}, Point{1, 0}, 111195},
+		{Point{55.7558, 37.6173}, Point{59.9343, 30.3351}, 633000}, // Moscow - Saint Petersburg
+	}
+	for i, test := range tests {
+		d := Distance(test.a, test.b)
+		if math.Abs(d-test.want) > test.want/1000+1 {
+			t.Errorf("#%d: expected ~%v, got %v", i, test.want, d)
+		}
+	}
+}
+
+func TestRead(t *testing.T) {
+	g := gorka.New()
+	coords, err := Read(g, strings.NewReader(sample), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if g.NodesCount() != 5 {
+		t.Errorf("expected 5 nodes, got %d", g.NodesCount())
+	}
+	if _, ok := g.NodeByLabel("6"); ok {
+		t.Errorf("node of a footway is added")
+	}
+
+	var tests = []struct {
+		a, b string
+		want bool
+	}{
+		{"1", "2", true},
+		{"2", "1", true},
+		{"2", "3", true},
+		{"3", "2", true},
+		{"3", "4", true},
+		{"4", "3", false},
+		{"4", "5", true},
+		{"5", "4", false},
+		{"1", "3", false},
+	}
+	for i, test := range tests {
+		a, _ := g.NodeByLabel(test.a)
+		b, _ := g.NodeByLabel(test.b)
+		if a == nil || b == nil {
+			t.Errorf("#%d: node is missing", i)
+			continue
+		}
+		if got := g.HasEdgeBetween(a, b); got != test.want {
+			t.Errorf("#%d: edge %s -> %s: expected %v, got %v", i, test.a, test.b, test.want, got)
+		}
+	}
+
+	n1, _ := g.NodeByLabel("1")
+	n2, _ := g.NodeByLabel("2")
+	if p := coords[n1.ID()]; p != (Point{55.75, 37.61}) {
+		t.Errorf("wrong coordinates of node 1: %v", p)
+	}
+	var e types.Edge
+	g.NodeEdgeIter(n1, func(x types.Edge) bool {
+		if x.Dst().ID() == n2.ID() {
+			e = x
+		}
+		return e == nil
+	})
+	if want := Distance(Point{55.75, 37.61}, Point{55.751, 37.61}); math.Abs(float64(e.Wieght())-want) > 0.01 {
+		t.Errorf("expected edge length %v, got %v", want, e.Wieght())
+	}
+	if math.Abs(float64(e.Wieght())-111.2) > 0.1 {
+		t.Errorf("expected edge length about 111.2m, got %v", e.Wieght())
+	}
+}
+
+func TestReadOptions(t *testing.T) {
+	var tests = []struct {
+		opt   Options
+		nodes int
+		edges int
+	}{
+		{Options{}, 5, 6},
+		{Options{IgnoreOneway: true}, 5, 8},
+		{Options{Highways: []string{"residential"}}, 3, 4},
+		{Options{Highways: []string{"footway"}}, 2, 2},
+		{Options{Highways: []string{"*"}}, 6, 8},
+	}
+	for i, test := range tests {
+		g := gorka.New()
+		coords, err := Read(g, strings.NewReader(sample), &test.opt)
+		if err != nil {
+			t.Errorf("#%d: %s", i, err)
+			continue
+		}
+		if g.NodesCount() != test.nodes {
+			t.Errorf("#%d: expected %d nodes, got %d", i, test.nodes, g.NodesCount())
+		}
+		if len(coords) != test.nodes {
+			t.Errorf("#%d: expected %d coordinates, got %d", i, test.nodes, len(coords))
+		}
+		if g.EdgesCount() != test.edges {
+			t.Errorf("#%d: expected %d edges, got %d", i, test.edges, g.EdgesCount())
+		}
+	}
+}
+
+func TestReadImplicitOneway(t *testing.T) {
+	const data = `<osm>
+ <node id="1" lat="0" lon="0"/>
+ <node id="2" lat="0" lon="0.001"/>
+ <node id="3" lat="0.001" lon="0.001"/>
+ <node id="4" lat="0.001" lon="0"/>
+ <way id="1"><nd ref="1"/><nd ref="2"/><tag k="highway" v="motorway"/></way>
+ <way id="2"><nd ref="2"/><nd ref="3"/><nd ref="4"/><nd ref="2"/>
+  <tag k="highway" v="primary"/><tag k="junction" v="roundabout"/></way>
+ <way id="3"><nd ref="4"/><nd ref="1"/>
+  <tag k="highway" v="motorway"/><tag k="oneway" v="no"/></way>
+</osm>`
+	g := gorka.New()
+	if _, err := Read(g, strings.NewReader(data), nil); err != nil {
+		t.Fatal(err)
+	}
+	if g.EdgesCount() != 6 {
+		t.Errorf("expected 6 edges, got %d", g.EdgesCount())
+	}
+	n2, _ := g.NodeByLabel("2")
+	n3, _ := g.NodeByLabel("3")
+	if g.HasEdgeBetween(n3, n2) {
+		t.Errorf("roundabout is not one-way")
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	var tests = []string{
+		`<osm><node id="1" lat="x" lon="0"/></osm>`,
+		`<osm><way id="x"></way></osm>`,
+		`<osm><way id="1"><nd ref="a"/></way></osm>`,
+		`<osm><way id="1"><nd ref="1"/>`,
+		`<osm><node id="1"`,
+	}
+	for i, test := range tests {
+		if _, err := Read(gorka.New(), strings.NewReader(test), nil); err == nil {
+			t.Errorf("#%d: expected error", i)
+		}
+	}
+	if _, err := Read(nil, strings.NewReader(sample), nil); err == nil {
+		t.Errorf("expected error on nil graph")
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "map.osm.gz")
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := gzip.NewWriter(f)
+	zw.Write([]byte(sample))
+	zw.Close()
+	f.Close()
+
+	g := gorka.New()
+	if _, err := ReadFile(g, name, nil); err != nil {
+		t.Fatal(err)
+	}
+	if g.EdgesCount() != 6 {
+		t.Errorf("expected 6 edges, got %d", g.EdgesCount())
+	}
+}
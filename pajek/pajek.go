@@ -0,0 +1,323 @@
+package pajek
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/iimos/gorka/internal/graphutil"
+	"github.com/iimos/gorka/types"
+)
+
+// Options controls how a graph is written
+type Options struct {
+	// Name is written as `*Network` name when not empty
+	Name string
+	// Undirected writes `*Edges` instead of `*Arcs`, see graphutil.SkipOpposite
+	Undirected bool
+	// Weights writes edge weights
+	Weights bool
+}
+
+// Read reads a network in Pajek .net format.
+//
+// Vertices are labeled with their labels from `*Vertices` section, vertices
+// without a label with their numbers. Sections `*Arcs` and `*Arcslist` add
+// directed edges, `*Edges` and `*Edgeslist` add edges in both directions,
+// `*Matrix` adds an edge for every non-zero value. Edges without weight get
+// weight 1. Coordinates and drawing parameters are ignored.
+func Read(g types.Graph, r io.Reader) error {
+	if g == nil {
+		return errors.New("graph is empty")
+	}
+
+	scan := bufio.NewScanner(r)
+	scan.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	line := 0
+	errorf := func(format string, args ...interface{}) error {
+		return fmt.Errorf("line %d: %s", line, fmt.Sprintf(format, args...))
+	}
+
+	var nodes []types.Node
+	labels := map[int]string{}
+	created := false
+	// create makes the nodes once vertex labels are read
+	create := func() {
+		if created {
+			return
+		}
+		created = true
+		for i := range nodes {
+			label, ok := labels[i+1]
+			if !ok {
+				label = strconv.Itoa(i + 1)
+			}
+			nodes[i] = graphutil.Obtain(g, label)
+		}
+	}
+	node := func(s string) (types.Node, error) {
+		i, err := strconv.Atoi(s)
+		if err != nil || i < 1 || i > len(nodes) {
+			return nil, errorf("wrong vertex '%s'", s)
+		}
+		return nodes[i-1], nil
+	}
+
+	section := ""
+	row := 0
+	// endSection checks that the matrix is complete
+	endSection := func() error {
+		if section == "*matrix" && row != len(nodes) {
+			return errorf("matrix has %d rows, expected %d", row, len(nodes))
+		}
+		return nil
+	}
+	for scan.Scan() {
+		line++
+		text := strings.TrimSpace(scan.Text())
+		if text == "" || text[0] == '%' {
+			continue
+		}
+
+		if text[0] == '*' {
+			if err := endSection(); err != nil {
+				return err
+			}
+			fields := strings.Fields(text)
+			section = strings.ToLower(fields[0])
+			switch section {
+			case "*network":
+			case "*vertices":
+				if nodes != nil {
+					return errorf("duplicate *Vertices section")
+				}
+				if len(fields) < 2 {
+					return errorf("vertices count is missing")
+				}
+				count, err := strconv.Atoi(fields[1])
+				if err != nil || count < 0 {
+					return errorf("wrong vertices count '%s'", fields[1])
+				}
+				nodes = make([]types.Node, count)
+			case "*arcs", "*edges", "*arcslist", "*edgeslist", "*matrix":
+				if nodes == nil {
+					return errorf("*Vertices section is missing")
+				}
+				create()
+				row = 0
+			default:
+				return errorf("unknown section '%s'", fields[0])
+			}
+			continue
+		}
+
+		switch section {
+		case "*vertices":
+			fields, err := split(text)
+			if err != nil {
+				return errorf("%s", err)
+			}
+			i, err := strconv.Atoi(fields[0])
+			if err != nil || i < 1 || i > len(nodes) {
+				return errorf("wrong vertex '%s'", fields[0])
+			}
+			if len(fields) > 1 {
+				labels[i] = fields[1]
+			}
+
+		case "*arcs", "*edges":
+			fields, err := split(text)
+			if err != nil {
+				return errorf("%s", err)
+			}
+			if len(fields) < 2 {
+				return errorf("expected 'from to [weight]'")
+			}
+			src, err := node(fields[0])
+			if err != nil {
+				return err
+			}
+			dst, err := node(fields[1])
+			if err != nil {
+				return err
+			}
+			w := float32(1)
+			if len(fields) > 2 {
+				f, err := strconv.ParseFloat(fields[2], 32)
+				if err != nil {
+					return errorf("wrong weight '%s'", fields[2])
+				}
+				w = float32(f)
+			}
+			connect(g, src, dst, w, section == "*edges")
+
+		case "*arcslist", "*edgeslist":
+			fields := strings.Fields(text)
+			src, err := node(fields[0])
+			if err != nil {
+				return err
+			}
+			for _, f := range fields[1:] {
+				dst, err := node(f)
+				if err != nil {
+					return err
+				}
+				connect(g, src, dst, 1, section == "*edgeslist")
+			}
+
+		case "*matrix":
+			row++
+			if row > len(nodes) {
+				return errorf("matrix has more than %d rows", len(nodes))
+			}
+			fields := strings.Fields(text)
+			if len(fields) != len(nodes) {
+				return errorf("expected %d values, got %d", len(nodes), len(fields))
+			}
+			for j, f := range fields {
+				v, err := strconv.ParseFloat(f, 32)
+				if err != nil {
+					return errorf("wrong value '%s'", f)
+				}
+				if v != 0 {
+					g.AddEdge(nodes[row-1], nodes[j], float32(v))
+				}
+			}
+
+		case "*network":
+			return errorf("unexpected line after *Network")
+
+		default:
+			return errorf("data outside of a section")
+		}
+	}
+	if err := scan.Err(); err != nil {
+		return err
+	}
+	if err := endSection(); err != nil {
+		return err
+	}
+	if nodes == nil {
+		return errors.New("*Vertices section is missing")
+	}
+	// network of isolated vertices
+	create()
+	return nil
+}
+
+func connect(g types.Graph, src, dst types.Node, w float32, undirected bool) {
+	if undirected {
+		g.AddBiEdge(src, dst, w)
+	} else {
+		g.AddEdge(src, dst, w)
+	}
+}
+
+// split splits a line into fields, fields may be double-quoted
+func split(line string) ([]string, error) {
+	var fields []string
+	for {
+		line = strings.TrimLeft(line, " \t")
+		if line == "" {
+			return fields, nil
+		}
+		if line[0] == '"' {
+			i := strings.IndexByte(line[1:], '"')
+			if i < 0 {
+				return nil, errors.New("unterminated quoted field")
+			}
+			fields = append(fields, line[1:i+1])
+			line = line[i+2:]
+			continue
+		}
+		i := strings.IndexAny(line, " \t")
+		if i < 0 {
+			i = len(line)
+		}
+		fields = append(fields, line[:i])
+		line = line[i:]
+	}
+}
+
+// ReadFile reads a Pajek network from the named file
+func ReadFile(g types.Graph, name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := Read(g, f); err != nil {
+		return fmt.Errorf("%s: %s", name, err)
+	}
+	return nil
+}
+
+// Write writes the graph in Pajek .net format. Vertices are numbered from 1
+// in NodeIter order, labels are written when not empty.
+func Write(w io.Writer, g types.Graph, opt *Options) error {
+	if opt == nil {
+		opt = &Options{}
+	}
+
+	index := make([]int, g.MaxNodeID()+1)
+	count := 0
+	g.NodeIter(func(n types.Node) bool {
+		count++
+		index[n.ID()] = count
+		return true
+	})
+
+	b := bufio.NewWriter(w)
+	if opt.Name != "" {
+		b.WriteString("*Network ")
+		b.WriteString(opt.Name)
+		b.WriteByte('\n')
+	}
+	fmt.Fprintf(b, "*Vertices %d\n", count)
+	var err error
+	g.NodeIter(func(n types.Node) bool {
+		b.WriteString(strconv.Itoa(index[n.ID()]))
+		if l := n.Label(); l != "" {
+			if strings.ContainsAny(l, "\"\n") {
+				err = fmt.Errorf("label of node %d can't be written: %q", n.ID(), l)
+				return false
+			}
+			b.WriteString(` "`)
+			b.WriteString(l)
+			b.WriteByte('"')
+		}
+		b.WriteByte('\n')
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	if opt.Undirected {
+		b.WriteString("*Edges\n")
+	} else {
+		b.WriteString("*Arcs\n")
+	}
+	g.NodeIter(func(n types.Node) bool {
+		for _, e := range graphutil.SortedEdges(g, n) {
+			d := e.Dst()
+			if opt.Undirected && graphutil.SkipOpposite(g, n, d) {
+				continue
+			}
+			b.WriteString(strconv.Itoa(index[n.ID()]))
+			b.WriteByte(' ')
+			b.WriteString(strconv.Itoa(index[d.ID()]))
+			if opt.Weights {
+				b.WriteByte(' ')
+				b.WriteString(strconv.FormatFloat(float64(e.Wieght()), 'g', -1, 32))
+			}
+			b.WriteByte('\n')
+		}
+		return true
+	})
+	return b.Flush()
+}
@@ -0,0 +1,132 @@
+package pajek
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/iimos/gorka"
+	"github.com/iimos/gorka/internal/graphtest"
+)
+
+func TestRead(t *testing.T) {
+	type e struct {
+		a, b string
+		w    float32
+	}
+	type tcase struct {
+		text      string
+		nodeCount int
+		edgeCount int
+		edges     []e
+	}
+	cases := []tcase{
+		tcase{`*Network sample
+% comment
+*Vertices 4
+1 "New York" 0.1 0.2 0.5 ic Red
+2 Boston
+3 "c"
+*Arcs
+1 2 1.5 c Blue
+3 1
+*Edges
+2 3 2
+`, 4, 4, []e{{"New York", "Boston", 1.5}, {"c", "New York", 1}, {"Boston", "c", 2}, {"c", "Boston", 2}}},
+		tcase{"*vertices 3\n*arcslist\n1 2 3\n*edgeslist\n3 1\n", 3, 3,
+			[]e{{"1", "2", 1}, {"1", "3", 1}, {"3", "1", 1}}},
+		tcase{"*Vertices 3\n*Matrix\n0 1 0\n0 0 2.5\n1 0 0\n", 3, 3,
+			[]e{{"1", "2", 1}, {"2", "3", 2.5}, {"3", "1", 1}}},
+		tcase{"*Vertices 2\n", 2, 0, nil},
+		tcase{"*Vertices 5 2\n1 \"a\"\n*Edges\n1 5\n", 5, 2, []e{{"a", "5", 1}}},
+	}
+	for i, c := range cases {
+		g := gorka.New()
+		if err := Read(g, strings.NewReader(c.text)); err != nil {
+			t.Errorf("#%d: %s", i, err)
+			continue
+		}
+		if g.NodesCount() != c.nodeCount {
+			t.Errorf("#%d: expected %d nodes, got %d", i, c.nodeCount, g.NodesCount())
+		}
+		if g.EdgesCount() != c.edgeCount {
+			t.Errorf("#%d: expected %d edges, got %d", i, c.edgeCount, g.EdgesCount())
+		}
+		for _, edge := range c.edges {
+			if w, ok := graphtest.Weight(g, edge.a, edge.b); !ok || w != edge.w {
+				t.Errorf("#%d: expected edge %s -> %s of weight %v", i, edge.a, edge.b, edge.w)
+			}
+		}
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	cases := []string{
+		"",
+		"1 2\n",
+		"*Arcs\n1 2\n",
+		"*Vertices\n",
+		"*Vertices x\n",
+		"*Vertices 2\n*Vertices 2\n",
+		"*Vertices 2\n3 \"c\"\n",
+		"*Vertices 2\n1 \"c\n",
+		"*Vertices 2\n*Arcs\n1\n",
+		"*Vertices 2\n*Arcs\n1 3\n",
+		"*Vertices 2\n*Arcs\n1 2 x\n",
+		"*Vertices 2\n*Arcslist\n1 0\n",
+		"*Vertices 2\n*Matrix\n0 1\n",
+		"*Vertices 2\n*Matrix\n0 1\n1 0\n1 1\n",
+		"*Vertices 2\n*Matrix\n0 x\n",
+		"*Vertices 2\n*Hyperedges\n",
+		"*Network n\nfoo\n",
+	}
+	for i, c := range cases {
+		if err := Read(gorka.New(), strings.NewReader(c)); err == nil {
+			t.Errorf("#%d: expected error", i)
+		}
+	}
+	if err := Read(nil, strings.NewReader("*Vertices 1\n")); err == nil {
+		t.Errorf("expected error on nil graph")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	g := gorka.New()
+	a, _ := g.NewNode("New York")
+	b, _ := g.NewNode("b")
+	c, _ := g.NewNode("")
+	g.AddEdge(a, b, 2)
+	g.AddBiEdge(b, c, 0.5)
+
+	var tests = []struct {
+		opt  *Options
+		want string
+	}{
+		{nil, "*Vertices 3\n1 \"New York\"\n2 \"b\"\n3\n*Arcs\n1 2\n2 3\n3 2\n"},
+		{&Options{Name: "net", Undirected: true, Weights: true},
+			"*Network net\n*Vertices 3\n1 \"New York\"\n2 \"b\"\n3\n*Edges\n1 2 2\n2 3 0.5\n"},
+	}
+	for i, test := range tests {
+		var buf bytes.Buffer
+		if err := Write(&buf, g, test.opt); err != nil {
+			t.Errorf("#%d: %s", i, err)
+			continue
+		}
+		if buf.String() != test.want {
+			t.Errorf("#%d: expected\n%s\ngot\n%s", i, test.want, buf.String())
+		}
+		g2 := gorka.New()
+		if err := Read(g2, &buf); err != nil {
+			t.Errorf("#%d: can't read written graph: %s", i, err)
+			continue
+		}
+		if g2.NodesCount() != 3 {
+			t.Errorf("#%d: round trip: expected 3 nodes, got %d", i, g2.NodesCount())
+		}
+	}
+
+	g.NewNode(`"quoted"`)
+	if err := Write(&bytes.Buffer{}, g, nil); err == nil {
+		t.Errorf("expected error on label with quotes")
+	}
+}
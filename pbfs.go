@@ -0,0 +1,197 @@
+package gorka
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// ParallelBFSOptions controls ParallelBFS
+type ParallelBFSOptions struct {
+	// Workers is the number of goroutines, default is GOMAXPROCS
+	Workers int
+	// Alpha and Beta tune switching between top-down and bottom-up steps,
+	// default is 14 and 24 as suggested by Beamer et al.
+	// Search goes bottom-up when edges to check from the frontier exceed
+	// 1/Alpha of edges of unvisited nodes, and back top-down when the
+	// frontier is smaller than 1/Beta of nodes.
+	Alpha, Beta int
+}
+
+// BFSResult holds distances and the BFS tree found by ParallelBFS.
+// Both slices are indexed by node ID.
+type BFSResult struct {
+	// Dist is the distance from the start in hops, -1 for unreached nodes
+	Dist []int
+	// Parent is the ID of the node a node was reached from,
+	// the start is its own parent and unreached nodes have 0
+	Parent []int
+}
+
+// Reached reports whether the node was reached from the start
+func (r *BFSResult) Reached(n Node) bool {
+	return n.ID() < len(r.Dist) && r.Dist[n.ID()] >= 0
+}
+
+// ParallelBFS is a level-synchronous breadth-first search over outgoing edges
+// running each level on several goroutines. It is direction-optimizing
+// (Beamer, Asanović, Patterson, 2012): while the frontier is small every
+// frontier node claims its unvisited successors (top-down), and when the
+// frontier gets large every unvisited node looks for a predecessor in the
+// frontier over incoming edges (bottom-up), which checks far fewer edges
+// on low-diameter graphs. The graph must not be modified during the search.
+// When several nodes of a level can be a parent, which one is chosen is not
+// defined, distances are always the same as of a sequential BFS.
+func ParallelBFS(g Graph, start Node, opt *ParallelBFSOptions) *BFSResult {
+	if opt == nil {
+		opt = &ParallelBFSOptions{}
+	}
+	workers := opt.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	alpha, beta := opt.Alpha, opt.Beta
+	if alpha <= 0 {
+		alpha = 14
+	}
+	if beta <= 0 {
+		beta = 24
+	}
+
+	size := g.MaxNodeID() + 1
+	res := &BFSResult{
+		Dist:   make([]int, size),
+		Parent: make([]int, size),
+	}
+	for i := range res.Dist {
+		res.Dist[i] = -1
+	}
+	if start == nil {
+		return res
+	}
+
+	nodes := make([]Node, size)
+	degree := make([]int, size)
+	unexplored := 0 // edges of unvisited nodes
+	g.NodeIter(func(n Node) bool {
+		nodes[n.ID()] = n
+		degree[n.ID()] = g.OutDegree(n)
+		unexplored += degree[n.ID()]
+		return true
+	})
+	var inEdges func(n Node, cb func(e Edge) bool) // set by the first bottom-up step
+
+	// parent is claimed with CAS in top-down steps
+	parent := make([]int64, size)
+	for i := range parent {
+		parent[i] = -1
+	}
+	sid := start.ID()
+	parent[sid] = int64(sid)
+	res.Dist[sid] = 0
+	unexplored -= degree[sid]
+
+	frontier := []int{sid}
+	inFrontier := make([]bool, size)
+	bottomUp := false
+
+	for level := 1; len(frontier) > 0; level++ {
+		scout := 0 // edges to check from the frontier
+		for _, v := range frontier {
+			scout += degree[v]
+		}
+		if !bottomUp && scout > unexplored/alpha {
+			bottomUp = true
+		} else if bottomUp && len(frontier) < g.NodesCount()/beta {
+			bottomUp = false
+		}
+
+		var next []int
+		if bottomUp {
+			if inEdges == nil {
+				inEdges = inEdgeIter(g)
+			}
+			for i := range inFrontier {
+				inFrontier[i] = false
+			}
+			for _, v := range frontier {
+				inFrontier[v] = true
+			}
+			next = parallelChunks(size, workers, func(lo, hi int, out []int) []int {
+				for v := lo; v < hi; v++ {
+					if parent[v] >= 0 || nodes[v] == nil {
+						continue
+					}
+					inEdges(nodes[v], func(e Edge) bool {
+						if u := e.From().ID(); inFrontier[u] {
+							parent[v] = int64(u)
+							res.Dist[v] = level
+							out = append(out, v)
+							return false
+						}
+						return true
+					})
+				}
+				return out
+			})
+		} else {
+			next = parallelChunks(len(frontier), workers, func(lo, hi int, out []int) []int {
+				for _, u := range frontier[lo:hi] {
+					g.NodeEdgeIter(nodes[u], func(e Edge) bool {
+						v := e.Dst().ID()
+						if atomic.LoadInt64(&parent[v]) < 0 && atomic.CompareAndSwapInt64(&parent[v], -1, int64(u)) {
+							res.Dist[v] = level
+							out = append(out, v)
+						}
+						return true
+					})
+				}
+				return out
+			})
+		}
+
+		for _, v := range next {
+			unexplored -= degree[v]
+		}
+		frontier = next
+	}
+
+	for v, p := range parent {
+		if p >= 0 {
+			res.Parent[v] = int(p)
+		}
+	}
+	return res
+}
+
+// parallelChunks splits [0, n) between workers, calls fn for every chunk
+// and concatenates the results
+func parallelChunks(n, workers int, fn func(lo, hi int, out []int) []int) []int {
+	if workers > n {
+		workers = n
+	}
+	if workers <= 1 {
+		return fn(0, n, nil)
+	}
+	parts := make([][]int, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		lo, hi := n*w/workers, n*(w+1)/workers
+		wg.Add(1)
+		go func(w, lo, hi int) {
+			defer wg.Done()
+			parts[w] = fn(lo, hi, nil)
+		}(w, lo, hi)
+	}
+	wg.Wait()
+
+	total := 0
+	for _, p := range parts {
+		total += len(p)
+	}
+	res := make([]int, 0, total)
+	for _, p := range parts {
+		res = append(res, p...)
+	}
+	return res
+}
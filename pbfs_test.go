@@ -0,0 +1,132 @@
+package gorka
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/iimos/gorka/gralang"
+)
+
+// sparseRandom returns a graph of n nodes with m random edges
+func sparseRandom(n, m int, seed int64) Graph {
+	rnd := rand.New(rand.NewSource(seed))
+	g := newGraph()
+	for i := 0; i < n; i++ {
+		g.NewNode("")
+	}
+	for i := 0; i < m; i++ {
+		g.AddEdge(g.nodes[rnd.Intn(n)], g.nodes[rnd.Intn(n)], 1)
+	}
+	return g
+}
+
+func TestParallelBFS(t *testing.T) {
+	type tcase struct {
+		g     Graph
+		start int
+	}
+	lattice, _ := NewRegular(500, 4)
+	small := New()
+	gralang.Parse(small, "a -> b c; b -> d; c -> d; d -> a; x -> a")
+	cases := []tcase{
+		tcase{small, 1},
+		tcase{lattice, 7},
+		tcase{sparseRandom(2000, 3000, 1), 1},  // many unreached nodes
+		tcase{sparseRandom(2000, 40000, 2), 5}, // low diameter, goes bottom-up
+	}
+
+	for i, c := range cases {
+		start := c.g.(*graph).nodeMap[c.start]
+		expected := map[int]int{}
+		TraverseBreadthFirstVisit(c.g, start, nil, func(v Visit) bool {
+			expected[v.Node.ID()] = v.Depth
+			return true
+		})
+
+		for _, workers := range []int{1, 4} {
+			g := c.g
+			if workers == 1 {
+				// bottom-up steps on the index built from outgoing edges
+				g = outOnly{c.g}
+			}
+			for _, alpha := range []int{0, 1000000} {
+				res := ParallelBFS(g, start, &ParallelBFSOptions{Workers: workers, Alpha: alpha})
+				c.g.NodeIter(func(n Node) bool {
+					id := n.ID()
+					d, ok := expected[id]
+					if !ok {
+						d = -1
+					}
+					if res.Dist[id] != d || res.Reached(n) != ok {
+						t.Errorf("#%d (workers %d, alpha %d): node %d: wrong distance %d, expected %d",
+							i, workers, alpha, id, res.Dist[id], d)
+					}
+					p := res.Parent[id]
+					switch {
+					case !ok && p != 0:
+						t.Errorf("#%d: unreached node %d has parent %d", i, id, p)
+					case id == start.ID() && p != id:
+						t.Errorf("#%d: wrong parent of start %d", i, p)
+					case ok && id != start.ID():
+						pn := c.g.(*graph).nodeMap[p]
+						if !c.g.HasEdgeBetween(pn, n) || res.Dist[p] != d-1 {
+							t.Errorf("#%d: node %d has wrong parent %d", i, id, p)
+						}
+					}
+					return true
+				})
+			}
+		}
+	}
+
+	if res := ParallelBFS(small, nil, nil); len(res.Dist) == 0 || res.Dist[1] != -1 {
+		t.Errorf("nodes are reached without start")
+	}
+}
+
+var benchGraph = struct {
+	g     Graph
+	start Node
+}{}
+
+func benchBFSGraph() (Graph, Node) {
+	if benchGraph.g == nil {
+		g := sparseRandom(200000, 1600000, 1)
+		benchGraph.g, benchGraph.start = g, g.(*graph).nodes[0]
+	}
+	return benchGraph.g, benchGraph.start
+}
+
+func BenchmarkTraverseBreadthFirst(b *testing.B) {
+	g, start := benchBFSGraph()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		TraverseBreadthFirst(g, start, func(n Node) bool { return true })
+	}
+}
+
+func BenchmarkParallelBFS(b *testing.B) {
+	g, start := benchBFSGraph()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ParallelBFS(g, start, nil)
+	}
+}
+
+func BenchmarkParallelBFSTopDown(b *testing.B) {
+	g, start := benchBFSGraph()
+	opt := &ParallelBFSOptions{Alpha: 1 << 30}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ParallelBFS(g, start, opt)
+	}
+}
+
+func BenchmarkParallelBFSSingleWorker(b *testing.B) {
+	g, start := benchBFSGraph()
+	opt := &ParallelBFSOptions{Workers: 1}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ParallelBFS(g, start, opt)
+	}
+}
@@ -0,0 +1,139 @@
+package plantuml
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/iimos/gorka/internal/graphutil"
+	"github.com/iimos/gorka/types"
+)
+
+// Options controls how a graph is written
+type Options struct {
+	// Direction of the diagram: TB (or TD) for top to bottom and LR for
+	// left to right, default is TB
+	Direction string
+	// Shape is the element keyword used for nodes, default is "rectangle"
+	Shape string
+	// Undirected writes `--` links, see graphutil.SkipOpposite
+	Undirected bool
+	// Weights writes edge weights as link labels
+	Weights bool
+
+	// Nodes selects nodes to write, edges are written when both ends are
+	// selected. Nil means all nodes.
+	Nodes func(n types.Node) bool
+	// NodeLabel returns text of the node, nil means node labels,
+	// unlabeled nodes are written as their IDs
+	NodeLabel func(n types.Node) string
+	// EdgeLabel returns text of the link, it takes precedence over Weights
+	EdgeLabel func(e types.Edge) string
+}
+
+// Write writes the graph as PlantUML diagram. Nodes get aliases `n<ID>`
+// and their labels as text. PlantUML can't escape double quotes in names,
+// so they are replaced with single quotes.
+func Write(w io.Writer, g types.Graph, opt *Options) error {
+	if opt == nil {
+		opt = &Options{}
+	}
+	direction := ""
+	switch opt.Direction {
+	case "", "TB", "TD":
+	case "LR":
+		direction = "left to right direction"
+	default:
+		return fmt.Errorf("unknown direction '%s'", opt.Direction)
+	}
+	shape := opt.Shape
+	if shape == "" {
+		shape = "rectangle"
+	}
+	selected := func(n types.Node) bool {
+		return opt.Nodes == nil || opt.Nodes(n)
+	}
+
+	b := bufio.NewWriter(w)
+	b.WriteString("@startuml\n")
+	if direction != "" {
+		b.WriteString(direction)
+		b.WriteByte('\n')
+	}
+
+	g.NodeIter(func(n types.Node) bool {
+		if !selected(n) {
+			return true
+		}
+		label := graphutil.LabelOrID(n)
+		if opt.NodeLabel != nil {
+			label = opt.NodeLabel(n)
+		}
+		b.WriteString(shape)
+		b.WriteString(` "`)
+		b.WriteString(escape(label))
+		b.WriteString(`" as `)
+		b.WriteString(nodeID(n))
+		b.WriteByte('\n')
+		return true
+	})
+
+	link := " --> "
+	if opt.Undirected {
+		link = " -- "
+	}
+	g.NodeIter(func(n types.Node) bool {
+		if !selected(n) {
+			return true
+		}
+		for _, e := range graphutil.SortedEdges(g, n) {
+			d := e.Dst()
+			if !selected(d) {
+				continue
+			}
+			if opt.Undirected && graphutil.SkipOpposite(g, n, d) {
+				continue
+			}
+
+			text := ""
+			if opt.EdgeLabel != nil {
+				text = opt.EdgeLabel(e)
+			} else if opt.Weights {
+				text = strconv.FormatFloat(float64(e.Wieght()), 'g', -1, 32)
+			}
+
+			b.WriteString(nodeID(n))
+			b.WriteString(link)
+			b.WriteString(nodeID(d))
+			if text != "" {
+				b.WriteString(" : ")
+				b.WriteString(escape(text))
+			}
+			b.WriteByte('\n')
+		}
+		return true
+	})
+
+	b.WriteString("@enduml\n")
+	return b.Flush()
+}
+
+// String returns the graph as PlantUML diagram
+func String(g types.Graph, opt *Options) string {
+	var s strings.Builder
+	Write(&s, g, opt)
+	return s.String()
+}
+
+var escaper = strings.NewReplacer(`"`, "'", "\r", "", "\n", `\n`)
+
+// escape makes text safe to put in double quotes or after a colon
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+func nodeID(n types.Node) string {
+	return "n" + strconv.Itoa(n.ID())
+}
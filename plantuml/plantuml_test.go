@@ -0,0 +1,71 @@
+package plantuml
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/iimos/gorka"
+	"github.com/iimos/gorka/gralang"
+	"github.com/iimos/gorka/types"
+)
+
+func TestWrite(t *testing.T) {
+	type tcase struct {
+		s   string
+		opt *Options
+		out string
+	}
+	cases := []tcase{
+		tcase{"", nil, "@startuml\n@enduml\n"},
+		tcase{"a -> b c", nil,
+			"@startuml\nrectangle \"a\" as n1\nrectangle \"b\" as n2\nrectangle \"c\" as n3\nn1 --> n2\nn1 --> n3\n@enduml\n"},
+		tcase{"a -- b; b -> c", &Options{Direction: "LR", Shape: "node", Undirected: true},
+			"@startuml\nleft to right direction\nnode \"a\" as n1\nnode \"b\" as n2\nnode \"c\" as n3\nn1 -- n2\nn2 -- n3\n@enduml\n"},
+		tcase{"a -> b", &Options{Weights: true},
+			"@startuml\nrectangle \"a\" as n1\nrectangle \"b\" as n2\nn1 --> n2 : 1\n@enduml\n"},
+		tcase{"\"say\" -> b", &Options{Direction: "TD"},
+			"@startuml\nrectangle \"'say'\" as n1\nrectangle \"b\" as n2\nn1 --> n2\n@enduml\n"},
+		tcase{"a -> b -> c -> a", &Options{Nodes: func(n types.Node) bool { return n.Label() != "b" }},
+			"@startuml\nrectangle \"a\" as n1\nrectangle \"c\" as n3\nn3 --> n1\n@enduml\n"},
+	}
+
+	for i, c := range cases {
+		g := gorka.New()
+		if err := gralang.Parse(g, c.s); err != nil {
+			t.Errorf("#%d: parse error: %s", i, err)
+			continue
+		}
+		res := String(g, c.opt)
+		if res != c.out {
+			t.Errorf("#%d: wrong output:\n%s\nexpected:\n%s", i, res, c.out)
+		}
+	}
+}
+
+func TestWriteLabels(t *testing.T) {
+	g := gorka.New()
+	a, _ := g.NewNode("a")
+	b, _ := g.NewNode("")
+	g.AddEdge(a, b, 2.5)
+
+	opt := &Options{
+		Weights: true,
+		NodeLabel: func(n types.Node) string {
+			return strings.ToUpper(n.String()) + "\nnode"
+		},
+		EdgeLabel: func(e types.Edge) string {
+			return "uses"
+		},
+	}
+	res := String(g, opt)
+	if !strings.Contains(res, "n1 --> n2 : uses\n") {
+		t.Errorf("edge label is not written:\n%s", res)
+	}
+	if !strings.Contains(res, `\nnode" as n2`) {
+		t.Errorf("new line in node label is not escaped:\n%s", res)
+	}
+
+	if err := Write(&strings.Builder{}, g, &Options{Direction: "RL"}); err == nil {
+		t.Errorf("expected error on unsupported direction")
+	}
+}
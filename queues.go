@@ -6,7 +6,7 @@ import (
 
 // NodeQueue is a thread safe FIFO queue
 type NodeQueue struct {
-	q queue.Queue
+	q queue.SyncQueue[Node]
 }
 
 // Len returns number of nodes in the queue
@@ -16,21 +16,20 @@ func (q *NodeQueue) Len() int {
 
 // Push adds node to the end of the queue. Nils are ignored.
 func (q *NodeQueue) Push(n Node) {
-	q.q.Push(n)
+	if n != nil {
+		q.q.Push(n)
+	}
 }
 
 // Pop returns the first node and removes it from the queue. Returns nil only if empty.
 func (q *NodeQueue) Pop() Node {
-	item := q.q.Pop()
-	if item == nil {
-		return nil
-	}
-	return item.(Node)
+	n, _ := q.q.Pop()
+	return n
 }
 
 // NodeStack is a thread safe LIFO stack
 type NodeStack struct {
-	q queue.Stack
+	q queue.SyncStack[Node]
 }
 
 // Len returns number of nodes in the stack
@@ -40,14 +39,13 @@ func (q *NodeStack) Len() int {
 
 // Push adds node to the end of the queue. Nils are ignored.
 func (q *NodeStack) Push(n Node) {
-	q.q.Push(n)
+	if n != nil {
+		q.q.Push(n)
+	}
 }
 
 // Pop returns the first node and removes it from the queue. Returns nil only if empty.
 func (q *NodeStack) Pop() Node {
-	item := q.q.Pop()
-	if item == nil {
-		return nil
-	}
-	return item.(Node)
+	n, _ := q.q.Pop()
+	return n
 }
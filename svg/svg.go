@@ -0,0 +1,239 @@
+package svg
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/iimos/gorka/internal/graphutil"
+	"github.com/iimos/gorka/layout"
+	"github.com/iimos/gorka/types"
+)
+
+// Options controls how a graph is drawn
+type Options struct {
+	// Width and Height of the picture, default is 800x600
+	Width, Height int
+	// Layout gives node positions, nil means layout.ForceDirected
+	// with default options
+	Layout layout.Layout
+	// Title is written as the picture title when not empty
+	Title string
+	// Undirected draws edges without arrows, see graphutil.SkipOpposite
+	Undirected bool
+	// Weights writes edge weights next to edges
+	Weights bool
+	// NodeRadius is the radius of node circles, default is 10
+	NodeRadius float64
+	// MinStroke and MaxStroke are widths of the edges with the least and
+	// the greatest weight, default is 1 and 5. Widths of other edges are
+	// interpolated linearly.
+	MinStroke, MaxStroke float64
+	// NodeLabel returns text of the node, nil means node labels,
+	// unlabeled nodes are written as their IDs
+	NodeLabel func(n types.Node) string
+}
+
+const (
+	fontSize  = 12
+	edgeColor = "#555"
+)
+
+// Write draws the graph as standalone SVG document. Nodes are circles with
+// labels underneath, edges are lines whose thickness grows with weight.
+// In directed graphs a pair of opposite edges is drawn as two arcs.
+func Write(w io.Writer, g types.Graph, opt *Options) error {
+	if opt == nil {
+		opt = &Options{}
+	}
+	width, height := float64(opt.Width), float64(opt.Height)
+	if width <= 0 {
+		width = 800
+	}
+	if height <= 0 {
+		height = 600
+	}
+	r := opt.NodeRadius
+	if r <= 0 {
+		r = 10
+	}
+	minStroke, maxStroke := opt.MinStroke, opt.MaxStroke
+	if minStroke <= 0 {
+		minStroke = 1
+	}
+	if maxStroke <= 0 {
+		maxStroke = 5
+	}
+	l := opt.Layout
+	if l == nil {
+		l = layout.ForceDirected(g, nil)
+	}
+
+	var err error
+	g.NodeIter(func(n types.Node) bool {
+		if _, ok := l[n.ID()]; !ok {
+			err = fmt.Errorf("node %s has no position", n)
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	pos := fit(l, width, height, r+fontSize+4)
+
+	type edge struct {
+		e      types.Edge
+		curved bool
+	}
+	var edges []edge
+	minW, maxW := math.Inf(1), math.Inf(-1)
+	g.NodeIter(func(n types.Node) bool {
+		for _, e := range graphutil.SortedEdges(g, n) {
+			d := e.Dst()
+			opposite := d.ID() != n.ID() && g.HasEdgeBetween(d, n)
+			if opt.Undirected && graphutil.SkipOpposite(g, n, d) {
+				// opposite edge is drawn already
+				continue
+			}
+			edges = append(edges, edge{e: e, curved: opposite && !opt.Undirected})
+			minW = math.Min(minW, float64(e.Wieght()))
+			maxW = math.Max(maxW, float64(e.Wieght()))
+		}
+		return true
+	})
+	stroke := func(wt float32) float64 {
+		if maxW <= minW {
+			return minStroke
+		}
+		return minStroke + (float64(wt)-minW)/(maxW-minW)*(maxStroke-minStroke)
+	}
+
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %s %s">`+"\n",
+		num(width), num(height), num(width), num(height))
+	if opt.Title != "" {
+		b.WriteString("<title>")
+		xml.EscapeText(b, []byte(opt.Title))
+		b.WriteString("</title>\n")
+	}
+	if !opt.Undirected {
+		fmt.Fprintf(b, `<defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerUnits="userSpaceOnUse" `+
+			`markerWidth="10" markerHeight="10" orient="auto"><path d="M0,0 L10,5 L0,10 z" fill="%s"/></marker></defs>`+"\n", edgeColor)
+	}
+
+	fmt.Fprintf(b, `<g fill="none" stroke="%s">`+"\n", edgeColor)
+	var labels strings.Builder
+	for _, ed := range edges {
+		src, dst := pos[ed.e.From().ID()], pos[ed.e.Dst().ID()]
+		var d string
+		var mid layout.Point
+		switch {
+		case ed.e.From().ID() == ed.e.Dst().ID():
+			// self-loop above the node
+			d = fmt.Sprintf("M%s,%s C%s,%s %s,%s %s,%s",
+				num(src.X-r*0.7), num(src.Y-r*0.7), num(src.X-r*2.5), num(src.Y-r*3.5),
+				num(src.X+r*2.5), num(src.Y-r*3.5), num(src.X+r*0.7), num(src.Y-r*0.7))
+			mid = layout.Point{X: src.X, Y: src.Y - r*2.8}
+		case ed.curved:
+			// both edges of the pair bend to their right
+			dx, dy := dst.X-src.X, dst.Y-src.Y
+			c := layout.Point{X: (src.X+dst.X)/2 - dy*0.15, Y: (src.Y+dst.Y)/2 + dx*0.15}
+			a, z := towards(src, c, r), towards(dst, c, r)
+			d = fmt.Sprintf("M%s,%s Q%s,%s %s,%s", num(a.X), num(a.Y), num(c.X), num(c.Y), num(z.X), num(z.Y))
+			mid = layout.Point{X: (a.X + 2*c.X + z.X) / 4, Y: (a.Y + 2*c.Y + z.Y) / 4}
+		default:
+			a, z := towards(src, dst, r), towards(dst, src, r)
+			d = fmt.Sprintf("M%s,%s L%s,%s", num(a.X), num(a.Y), num(z.X), num(z.Y))
+			mid = layout.Point{X: (a.X + z.X) / 2, Y: (a.Y + z.Y) / 2}
+		}
+
+		fmt.Fprintf(b, `<path d="%s" stroke-width="%s"`, d, num(stroke(ed.e.Wieght())))
+		if !opt.Undirected {
+			b.WriteString(` marker-end="url(#arrow)"`)
+		}
+		b.WriteString("/>\n")
+
+		if opt.Weights {
+			fmt.Fprintf(&labels, `<text x="%s" y="%s">%s</text>`+"\n", num(mid.X), num(mid.Y),
+				strconv.FormatFloat(float64(ed.e.Wieght()), 'g', -1, 32))
+		}
+	}
+	b.WriteString("</g>\n")
+	if labels.Len() > 0 {
+		fmt.Fprintf(b, `<g font-family="sans-serif" font-size="%d" text-anchor="middle" fill="%s">`+"\n", fontSize-2, edgeColor)
+		b.WriteString(labels.String())
+		b.WriteString("</g>\n")
+	}
+
+	fmt.Fprintf(b, `<g font-family="sans-serif" font-size="%d" text-anchor="middle">`+"\n", fontSize)
+	g.NodeIter(func(n types.Node) bool {
+		p := pos[n.ID()]
+		label := graphutil.LabelOrID(n)
+		if opt.NodeLabel != nil {
+			label = opt.NodeLabel(n)
+		}
+		fmt.Fprintf(b, `<circle cx="%s" cy="%s" r="%s" fill="#fff" stroke="#333" stroke-width="1.5"/>`+"\n",
+			num(p.X), num(p.Y), num(r))
+		if label != "" {
+			fmt.Fprintf(b, `<text x="%s" y="%s">`, num(p.X), num(p.Y+r+fontSize))
+			xml.EscapeText(b, []byte(label))
+			b.WriteString("</text>\n")
+		}
+		return true
+	})
+	b.WriteString("</g>\n</svg>\n")
+	return b.Flush()
+}
+
+// String returns the graph drawn as SVG document
+func String(g types.Graph, opt *Options) string {
+	var s strings.Builder
+	Write(&s, g, opt)
+	return s.String()
+}
+
+// fit scales and centers the layout to the picture keeping the aspect ratio
+func fit(l layout.Layout, width, height, margin float64) layout.Layout {
+	min, max := l.Bounds()
+	w, h := max.X-min.X, max.Y-min.Y
+	availW, availH := math.Max(width-2*margin, 0), math.Max(height-2*margin, 0)
+
+	scale := 0.0
+	switch {
+	case w > 0 && h > 0:
+		scale = math.Min(availW/w, availH/h)
+	case w > 0:
+		scale = availW / w
+	case h > 0:
+		scale = availH / h
+	}
+
+	res := make(layout.Layout, len(l))
+	for id, p := range l {
+		res[id] = layout.Point{
+			X: width/2 + (p.X-(min.X+max.X)/2)*scale,
+			Y: height/2 + (p.Y-(min.Y+max.Y)/2)*scale,
+		}
+	}
+	return res
+}
+
+// towards returns the point at distance d from a in direction of b
+func towards(a, b layout.Point, d float64) layout.Point {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	l := math.Hypot(dx, dy)
+	if l == 0 {
+		return a
+	}
+	return layout.Point{X: a.X + dx/l*d, Y: a.Y + dy/l*d}
+}
+
+// num formats a coordinate
+func num(f float64) string {
+	return strconv.FormatFloat(math.Round(f*10)/10, 'f', -1, 64)
+}
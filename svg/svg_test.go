@@ -0,0 +1,117 @@
+package svg
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/iimos/gorka"
+	"github.com/iimos/gorka/gralang"
+	"github.com/iimos/gorka/layout"
+	"github.com/iimos/gorka/types"
+)
+
+// elements counts XML elements by name and checks the document is well-formed
+func elements(t *testing.T, s string) map[string]int {
+	count := make(map[string]int)
+	dec := xml.NewDecoder(strings.NewReader(s))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("malformed svg: %s\n%s", err, s)
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			count[se.Name.Local]++
+		}
+	}
+	return count
+}
+
+func TestWrite(t *testing.T) {
+	type tcase struct {
+		s                     string
+		opt                   *Options
+		circles, paths, texts int
+	}
+	cases := []tcase{
+		tcase{"", nil, 0, 1, 0},
+		tcase{"a -> b c", nil, 3, 2 + 1, 3},
+		tcase{"a -> b -> a", nil, 2, 2 + 1, 2},
+		tcase{"a -- b -- c", &Options{Undirected: true}, 3, 2, 3},
+		tcase{"a -> b -> c", &Options{Weights: true}, 3, 2 + 1, 3 + 2},
+	}
+
+	for i, c := range cases {
+		g := gorka.New()
+		if err := gralang.Parse(g, c.s); err != nil {
+			t.Errorf("#%d: parse error: %s", i, err)
+			continue
+		}
+		res := String(g, c.opt)
+		count := elements(t, res)
+		if count["svg"] != 1 {
+			t.Errorf("#%d: svg root is missing:\n%s", i, res)
+		}
+		if count["circle"] != c.circles || count["path"] != c.paths || count["text"] != c.texts {
+			t.Errorf("#%d: wrong elements %v, expected %d circles, %d paths, %d texts:\n%s",
+				i, count, c.circles, c.paths, c.texts, res)
+		}
+		if c.opt != nil && c.opt.Undirected && strings.Contains(res, "marker") {
+			t.Errorf("#%d: undirected graph has arrows:\n%s", i, res)
+		}
+	}
+}
+
+func TestWriteLayout(t *testing.T) {
+	g := gorka.New()
+	a, _ := g.NewNode("a & b")
+	b, _ := g.NewNode("")
+	c, _ := g.NewNode("c")
+	g.AddEdge(a, b, 1)
+	g.AddEdge(b, c, 3)
+
+	opt := &Options{
+		Width:     200,
+		Height:    100,
+		Title:     "<test>",
+		Layout:    layout.Layout{a.ID(): {X: 0, Y: 0}, b.ID(): {X: 1, Y: 0}, c.ID(): {X: 2, Y: 0}},
+		MinStroke: 2,
+		MaxStroke: 4,
+	}
+	res := String(g, opt)
+	elements(t, res)
+
+	for _, s := range []string{
+		`width="200" height="100"`,
+		`<title>&lt;test&gt;</title>`,
+		`<circle cx="100" cy="50"`,
+		`>a &amp; b</text>`,
+		`>2</text>`,
+		`stroke-width="2" marker-end`,
+		`stroke-width="4" marker-end`,
+	} {
+		if !strings.Contains(res, s) {
+			t.Errorf("%q is missing in output:\n%s", s, res)
+		}
+	}
+
+	g.AddEdge(c, c, 1)
+	if res := String(g, opt); !strings.Contains(res, `<path d="M167,43 C`) {
+		t.Errorf("self-loop is missing:\n%s", res)
+	}
+
+	opt.NodeLabel = func(n types.Node) string { return "" }
+	if res := String(g, opt); strings.Contains(res, "<text") {
+		t.Errorf("empty labels are written:\n%s", res)
+	}
+
+	opt.Layout = layout.Layout{a.ID(): {X: 0, Y: 0}}
+	var s strings.Builder
+	if err := Write(&s, g, opt); err == nil {
+		t.Errorf("no error for incomplete layout")
+	}
+}
@@ -0,0 +1,33 @@
+package gorka
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/iimos/gorka/gralang"
+)
+
+// MarshalText implements encoding.TextMarshaler, the graph is written
+// in Gralang. Gralang has neither weights nor unlabeled nodes, so graphs
+// with edge weights other than 1 or unlabeled nodes are reported as errors,
+// MarshalBinary and MarshalJSON keep them. See gralang.Write for the rest
+// of graphs it can not represent.
+func (g *graph) MarshalText() ([]byte, error) {
+	var b bytes.Buffer
+	if err := gralang.Write(&b, g); err != nil {
+		return nil, fmt.Errorf("graph can't be written as text: %s", err)
+	}
+	return b.Bytes(), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It replaces the graph
+// content with a graph in Gralang. Include statements are rejected, so
+// the text never makes it read files.
+func (g *graph) UnmarshalText(text []byte) error {
+	ng := newGraph()
+	if err := gralang.Parse(ng, string(text)); err != nil {
+		return err
+	}
+	g.replace(ng)
+	return nil
+}
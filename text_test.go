@@ -0,0 +1,107 @@
+package gorka
+
+import (
+	"encoding"
+	"strings"
+	"testing"
+
+	"github.com/iimos/gorka/gralang"
+)
+
+var (
+	_ encoding.TextMarshaler   = (*graph)(nil)
+	_ encoding.TextUnmarshaler = (*graph)(nil)
+)
+
+func TestMarshalText(t *testing.T) {
+	type tcase struct {
+		s   string
+		out string
+	}
+	cases := []tcase{
+		tcase{"", ""},
+		tcase{"a -> b c; c -- d; e", "a b c d e\na -> b c\nc -> d\nd -> c\n"},
+		tcase{"z -> y -> x", "z y x\nz -> y\ny -> x\n"},
+	}
+
+	for i, c := range cases {
+		g := New()
+		if err := gralang.Parse(g, c.s); err != nil {
+			t.Errorf("#%d: parse error: %s", i, err)
+			continue
+		}
+		text, err := g.(*graph).MarshalText()
+		if err != nil {
+			t.Errorf("#%d: marshal error: %s", i, err)
+			continue
+		}
+		if string(text) != c.out {
+			t.Errorf("#%d: wrong text:\n%s\nexpected:\n%s", i, text, c.out)
+		}
+
+		g2 := New()
+		gralang.Parse(g2, "old -> content")
+		if err := g2.(*graph).UnmarshalText(text); err != nil {
+			t.Errorf("#%d: unmarshal error: %s", i, err)
+			continue
+		}
+		if !sameGraph(g, g2) {
+			t.Errorf("#%d: graph changed:\n%s\nexpected:\n%s", i, g2, g)
+		}
+	}
+}
+
+func TestMarshalTextErrors(t *testing.T) {
+	weighted := New()
+	a, _ := weighted.NewNode("a")
+	b, _ := weighted.NewNode("b")
+	weighted.AddEdge(a, b, 2)
+
+	unlabeled := New()
+	unlabeled.NewNode("")
+
+	spaced := New()
+	spaced.NewNode("a b")
+
+	for i, g := range []Graph{weighted, unlabeled, spaced} {
+		if _, err := g.(*graph).MarshalText(); err == nil {
+			t.Errorf("#%d: no error", i)
+		}
+	}
+	if _, err := weighted.(*graph).MarshalText(); err == nil || !strings.Contains(err.Error(), "weight 2") {
+		t.Errorf("weighted graph error doesn't tell about the weight: %v", err)
+	}
+
+	g := New()
+	if err := g.(*graph).UnmarshalText([]byte("a -> ")); err == nil {
+		t.Errorf("no error for broken text")
+	}
+
+	gralang.Parse(g, "old -> content")
+	if err := g.(*graph).UnmarshalText([]byte("include /etc/hostname")); err == nil {
+		t.Errorf("no error for include")
+	}
+	if g.NodesCount() != 2 || g.EdgesCount() != 1 {
+		t.Errorf("failed unmarshal changed the graph:\n%s", g)
+	}
+}
+
+func TestMarshalTextLongLists(t *testing.T) {
+	g := New()
+	hub, _ := g.NewNode("hub")
+	for i := 0; i < 40; i++ {
+		n, _ := g.NewNode("n" + strings.Repeat("x", i))
+		g.AddEdge(hub, n, 1)
+	}
+	text, err := g.(*graph).MarshalText()
+	if err != nil {
+		t.Fatalf("marshal error: %s", err)
+	}
+	if lines := strings.Count(string(text), "\n"); lines != 6 {
+		t.Errorf("wrong lines count %d:\n%s", lines, text)
+	}
+	g2 := New()
+	if err := g2.(*graph).UnmarshalText(text); err != nil || !sameGraph(g, g2) {
+		t.Errorf("graph changed: %v", err)
+	}
+}
@@ -1,71 +1,150 @@
 package gorka
 
 import (
-	"container/heap"
 	"errors"
+
+	"github.com/iimos/gorka/generic/queue"
 )
 
+// pushpoper is a queue or a stack of traversal steps,
+// queue.Queue gives breadth-first order and queue.Stack depth-first one
 type pushpoper interface {
-	Push(n Node)
-	Pop() Node
+	Push(s step)
+	Pop() (step, bool)
 	Len() int
 }
 
+// Direction tells which edges a traversal follows
+type Direction int
+
+const (
+	// Outgoing follows edges from a node to its successors
+	Outgoing Direction = iota
+	// Incoming follows edges backwards from a node to its predecessors
+	Incoming
+	// Both follows edges regardless of their direction, so the traversal
+	// reaches the weakly connected component of the start node
+	Both
+)
+
+// inEdgeIterer is implemented by graphs that index incoming edges of nodes
+type inEdgeIterer interface {
+	NodeInEdgeIter(n Node, cb func(e Edge) bool)
+}
+
+// inEdgeIter returns a function that calls cb for each incoming edge of a node.
+// Graphs without NodeInEdgeIter are scanned once to build the index of
+// incoming edges, so the graph must not change while the function is used.
+func inEdgeIter(g Graph) func(n Node, cb func(e Edge) bool) {
+	if ig, ok := g.(inEdgeIterer); ok {
+		return ig.NodeInEdgeIter
+	}
+	in := make(map[int][]Edge)
+	g.NodeIter(func(n Node) bool {
+		g.NodeEdgeIter(n, func(e Edge) bool {
+			in[e.Dst().ID()] = append(in[e.Dst().ID()], e)
+			return true
+		})
+		return true
+	})
+	return func(n Node, cb func(e Edge) bool) {
+		for _, e := range in[n.ID()] {
+			if !cb(e) {
+				break
+			}
+		}
+	}
+}
+
 type iterator struct {
-	graph   Graph
-	queue   pushpoper
-	visited []bool
-	reverse bool
+	graph    Graph
+	queue    pushpoper
+	visited  []bool
+	dir      Direction
+	maxDepth int
+	inEdges  func(n Node, cb func(e Edge) bool) // set on first use
+}
+
+// step is a node in the queue together with the way it was reached
+type step struct {
+	Node
+	parent Node
+	edge   Edge
+	depth  int
 }
 
 func (iter *iterator) isVisited(n Node) bool {
 	return iter.visited[n.ID()]
 }
 
-func (iter *iterator) next() (Node, error) {
-	n := iter.queue.Pop()
-	if n == nil {
-		return nil, nil
+// next returns the next node of the traversal, or a step with nil Node at the end
+func (iter *iterator) next() (step, error) {
+	for {
+		s, ok := iter.queue.Pop()
+		if !ok {
+			return step{}, nil
+		}
+		if iter.isVisited(s.Node) {
+			continue
+		}
+		iter.visited[s.ID()] = true
+		iter.expand(s)
+		return s, nil
 	}
+}
 
-	if iter.isVisited(n) {
-		return iter.next()
+// expand pushes unvisited neighbours of the node
+func (iter *iterator) expand(s step) {
+	if iter.maxDepth > 0 && s.depth >= iter.maxDepth {
+		return
 	}
-
-	iter.visited[n.ID()] = true
-
-	iter.graph.NodeEdgeIter(n, func(e Edge) bool {
-		d := e.Dst()
+	push := func(d Node, e Edge) {
 		if !iter.isVisited(d) {
-			iter.queue.Push(d)
+			iter.queue.Push(step{Node: d, parent: s.Node, edge: e, depth: s.depth + 1})
 		}
-		return true
-	})
-
-	return n, nil
+	}
+	if iter.dir != Incoming {
+		iter.graph.NodeEdgeIter(s.Node, func(e Edge) bool {
+			push(e.Dst(), e)
+			return true
+		})
+	}
+	if iter.dir != Outgoing {
+		if iter.inEdges == nil {
+			iter.inEdges = inEdgeIter(iter.graph)
+		}
+		iter.inEdges(s.Node, func(e Edge) bool {
+			push(e.From(), e)
+			return true
+		})
+	}
 }
 
-func newIterator(q pushpoper, g Graph, n Node) *iterator {
+func newIterator(q pushpoper, g Graph, starts ...Node) *iterator {
 	iter := iterator{
 		graph:   g,
 		queue:   q,
 		visited: make([]bool, g.MaxNodeID()+1),
 	}
-	iter.queue.Push(n)
+	for _, n := range starts {
+		if n != nil {
+			iter.queue.Push(step{Node: n})
+		}
+	}
 	return &iter
 }
 
 func traverse(iter *iterator, fn Callback) error {
 	for {
-		n, err := iter.next()
+		s, err := iter.next()
 		if err != nil {
 			return err
 		}
-		if n == nil {
+		if s.Node == nil {
 			return nil
 		}
 
-		further := fn(n)
+		further := fn(s.Node)
 		if !further {
 			return nil
 		}
@@ -77,16 +156,27 @@ type Callback func(n Node) (further bool)
 
 // TraverseBreadthFirst goes throught the graph from the start node and calls fn for each node
 func TraverseBreadthFirst(g Graph, start Node, fn Callback) error {
-	q := &NodeQueue{}
-	iter := newIterator(q, g, start)
+	return TraverseBreadthFirstDir(g, start, Outgoing, fn)
+}
+
+// TraverseBreadthFirstDir is TraverseBreadthFirst following edges in the given direction.
+// Incoming visits all ancestors of the start node, Both visits its weakly connected component.
+func TraverseBreadthFirstDir(g Graph, start Node, dir Direction, fn Callback) error {
+	iter := newIterator(&queue.Queue[step]{}, g, start)
+	iter.dir = dir
 	return traverse(iter, fn)
 }
 
 // TraverseDepthFirst goes throught the graph from the start node and calls fn for each node
 func TraverseDepthFirst(g Graph, start Node, fn Callback) error {
-	s := &NodeStack{}
-	iter := newIterator(s, g, start)
-	iter.reverse = true
+	return TraverseDepthFirstDir(g, start, Outgoing, fn)
+}
+
+// TraverseDepthFirstDir is TraverseDepthFirst following edges in the given direction.
+// Incoming visits all ancestors of the start node, Both visits its weakly connected component.
+func TraverseDepthFirstDir(g Graph, start Node, dir Direction, fn Callback) error {
+	iter := newIterator(&queue.Stack[step]{}, g, start)
+	iter.dir = dir
 	return traverse(iter, fn)
 }
 
@@ -100,22 +190,26 @@ func ShortestPath(g Graph, a, b Node) (path []Edge, len float32, err error) {
 	}
 
 	from := map[int]Edge{}
-	dist := map[int]float32{}
-	q := &nodeHeap{{node: a, dist: 0}}
+	dist := map[int]float32{a.ID(): 0} // a is never relaxed, so the path back ends at it
+	nodes := map[int]Node{a.ID(): a}
+	// every node is in the queue at most once, relaxing an edge decreases its key
+	q := queue.NewIndexedHeap[float32](g.MaxNodeID() + 1)
+	q.Push(a.ID(), 0)
 
 	for q.Len() > 0 {
-		d := heap.Pop(q).(distance)
-		g.NodeEdgeIter(d.node, func(e Edge) bool {
+		id, d, _ := q.Pop()
+		g.NodeEdgeIter(nodes[id], func(e Edge) bool {
 			n := e.Dst()
 			w := e.Wieght()
 			if w < 0 {
 				err = errors.New("Dijkstra's shortest path algorithm doesn't support negative weights")
 				return false
 			}
-			alt := d.dist + w
+			alt := d + w
 			curr, visited := dist[n.ID()]
 			if !visited || alt < curr {
-				heap.Push(q, distance{node: n, dist: alt})
+				q.Push(n.ID(), alt)
+				nodes[n.ID()] = n
 				dist[n.ID()] = alt
 				from[n.ID()] = e
 			}
@@ -126,18 +220,16 @@ func ShortestPath(g Graph, a, b Node) (path []Edge, len float32, err error) {
 		}
 	}
 
-	aid := b.ID()
-	bid := b.ID()
-	d, ok := dist[bid]
+	d, ok := dist[b.ID()]
 	if !ok {
 		return nil, 0, ErrPathNotFound
 	}
 
 	path = make([]Edge, 0, 8)
-	e, _ := from[bid]
-	for e != nil && e.From().ID() != aid {
+	for id := b.ID(); id != a.ID(); {
+		e := from[id]
 		path = append(path, e)
-		e = from[e.From().ID()]
+		id = e.From().ID()
 	}
 	reversePath(path)
 	return path, d, nil
@@ -150,25 +242,3 @@ func reversePath(path []Edge) {
 		path[i], path[opp] = path[opp], path[i]
 	}
 }
-
-// distance to the node
-type distance struct {
-	node Node
-	dist float32
-}
-
-// nodeHeap is a min-heap for storing distances to the node
-type nodeHeap []distance
-
-func (h nodeHeap) Len() int            { return len(h) }
-func (h nodeHeap) Less(i, j int) bool  { return h[i].dist < h[j].dist }
-func (h nodeHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
-func (h *nodeHeap) Push(x interface{}) { *h = append(*h, x.(distance)) }
-
-func (h *nodeHeap) Pop() interface{} {
-	old := *h
-	n := len(old)
-	x := old[n-1]
-	*h = old[0 : n-1]
-	return x
-}
@@ -1,7 +1,9 @@
 package gorka
 
 import (
+	"math/rand"
 	"reflect"
+	"sort"
 	"strings"
 	"testing"
 	"github.com/iimos/gorka/gralang"
@@ -153,3 +155,117 @@ func TestShortestPath(t *testing.T) {
 		}
 	}
 }
+
+// outOnly hides NodeInEdgeIter of the graph
+type outOnly struct {
+	Graph
+}
+
+func TestTraverseDirection(t *testing.T) {
+	type tcase struct {
+		s       string
+		start   string
+		dir     Direction
+		reached string
+	}
+	cases := [...]tcase{
+		tcase{"a -> b -> c; d -> b; e", "b", Outgoing, "b c"},
+		tcase{"a -> b -> c; d -> b; e", "b", Incoming, "a b d"},
+		tcase{"a -> b -> c; d -> b; e", "b", Both, "a b c d"},
+		tcase{"a -> b -> c; x -> c; y -> x", "a", Both, "a b c x y"},
+		tcase{"a -> b -> c; x -> c; y -> x", "c", Incoming, "a b c x y"},
+		tcase{"a -- b", "a", Incoming, "a b"},
+	}
+
+	for i, c := range cases {
+		g := New()
+		gralang.Parse(g, c.s)
+		start, _ := g.NodeByLabel(c.start)
+
+		for k := 0; k < 4; k++ {
+			bfs, gg := k%2 == 0, g
+			if k >= 2 {
+				gg = outOnly{g}
+			}
+			reached := []string{}
+			fn := func(n Node) bool {
+				reached = append(reached, n.Label())
+				return true
+			}
+			var err error
+			if bfs {
+				err = TraverseBreadthFirstDir(gg, start, c.dir, fn)
+			} else {
+				err = TraverseDepthFirstDir(gg, start, c.dir, fn)
+			}
+			if err != nil {
+				t.Errorf("#%d: traverse error: %s", i, err)
+			}
+			sort.Strings(reached)
+			if res := strings.Join(reached, " "); res != c.reached {
+				t.Errorf("#%d (bfs=%v, in-edges index=%v): wrong reached nodes %q, expected %q", i, bfs, k < 2, res, c.reached)
+			}
+		}
+	}
+}
+
+func TestShortestPathRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(4))
+	g := newGraph()
+	for i := 0; i < 200; i++ {
+		g.NewNode("")
+	}
+	for i := 0; i < 1500; i++ {
+		g.AddEdge(g.nodes[rnd.Intn(200)], g.nodes[rnd.Intn(200)], float32(rnd.Intn(10)))
+	}
+
+	// Bellman-Ford distances from the first node
+	a := g.nodes[0]
+	dist := map[int]float32{a.ID(): 0}
+	for changed := true; changed; {
+		changed = false
+		g.NodeIter(func(n Node) bool {
+			d, ok := dist[n.ID()]
+			if !ok {
+				return true
+			}
+			g.NodeEdgeIter(n, func(e Edge) bool {
+				if cur, ok := dist[e.Dst().ID()]; !ok || d+e.Wieght() < cur {
+					dist[e.Dst().ID()] = d + e.Wieght()
+					changed = true
+				}
+				return true
+			})
+			return true
+		})
+	}
+
+	for _, b := range g.nodes[1:] {
+		path, d, err := ShortestPath(g, a, b)
+		expected, ok := dist[b.ID()]
+		if !ok {
+			if err != ErrPathNotFound {
+				t.Errorf("node %d: expected ErrPathNotFound, got %v", b.ID(), err)
+			}
+			continue
+		}
+		if err != nil || d != expected {
+			t.Errorf("node %d: wrong distance %v, expected %v (%v)", b.ID(), d, expected, err)
+			continue
+		}
+
+		// the path goes from a to b and its length is the distance
+		at, length := a.ID(), float32(0)
+		for _, e := range path {
+			if e.From().ID() != at || !g.HasEdgeBetween(e.From(), e.Dst()) {
+				t.Errorf("node %d: broken path at edge %d->%d", b.ID(), e.From().ID(), e.Dst().ID())
+				break
+			}
+			at = e.Dst().ID()
+			length += e.Wieght()
+		}
+		if at != b.ID() || length != expected {
+			t.Errorf("node %d: path ends at %d with length %v, expected length %v", b.ID(), at, length, expected)
+		}
+	}
+}
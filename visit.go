@@ -0,0 +1,176 @@
+package gorka
+
+import (
+	"context"
+	"errors"
+
+	"github.com/iimos/gorka/generic/queue"
+)
+
+// Visit describes a node visited by a traversal and how it was reached
+type Visit struct {
+	Node   Node
+	Parent Node // node the traversal came from, nil for the start
+	Edge   Edge // edge between Parent and Node, nil for the start
+	Depth  int  // number of edges from the start along the traversal tree
+}
+
+func (s step) visit() Visit {
+	return Visit{Node: s.Node, Parent: s.parent, Edge: s.edge, Depth: s.depth}
+}
+
+// VisitCallback is a function that called for each node we visit
+type VisitCallback func(v Visit) (further bool)
+
+// TraverseOptions controls traversals
+type TraverseOptions struct {
+	// Direction of edges to follow, default is Outgoing
+	Direction Direction
+	// MaxDepth stops the traversal at nodes that far from the start,
+	// their neighbours are not visited. 0 means no limit.
+	MaxDepth int
+}
+
+func newIteratorOpt(q pushpoper, g Graph, start Node, opt *TraverseOptions) *iterator {
+	return newIteratorMulti(q, g, []Node{start}, opt)
+}
+
+func newIteratorMulti(q pushpoper, g Graph, starts []Node, opt *TraverseOptions) *iterator {
+	iter := newIterator(q, g, starts...)
+	if opt != nil {
+		iter.dir = opt.Direction
+		iter.maxDepth = opt.MaxDepth
+	}
+	return iter
+}
+
+func traverseVisit(iter *iterator, fn VisitCallback) error {
+	for {
+		s, err := iter.next()
+		if err != nil {
+			return err
+		}
+		if s.Node == nil {
+			return nil
+		}
+		if !fn(s.visit()) {
+			return nil
+		}
+	}
+}
+
+// TraverseBreadthFirstVisit goes through the graph from the start node in breadth-first
+// order and calls fn for each node with its depth, parent and the discovering edge.
+// Depth of a node is its distance from the start in hops.
+func TraverseBreadthFirstVisit(g Graph, start Node, opt *TraverseOptions, fn VisitCallback) error {
+	return traverseVisit(newIteratorOpt(&queue.Queue[step]{}, g, start, opt), fn)
+}
+
+// TraverseDepthFirstVisit goes through the graph from the start node in depth-first
+// order and calls fn for each node with its depth, parent and the discovering edge
+func TraverseDepthFirstVisit(g Graph, start Node, opt *TraverseOptions, fn VisitCallback) error {
+	return traverseVisit(newIteratorOpt(&queue.Stack[step]{}, g, start, opt), fn)
+}
+
+// ErrStop stops a traversal when returned by VisitErrCallback,
+// the traversal function returns nil then
+var ErrStop = errors.New("stop traversal")
+
+// VisitErrCallback is a function that called for each node we visit.
+// An error stops the traversal and is returned by the traversal function.
+type VisitErrCallback func(v Visit) error
+
+// TraverseBreadthFirstContext goes through the graph in breadth-first order from
+// all the start nodes at once: they all have depth 0 and every node gets the depth
+// of its distance to the closest start. The traversal stops on the first error
+// returned by fn or when the context is done, and returns that error.
+func TraverseBreadthFirstContext(ctx context.Context, g Graph, starts []Node, opt *TraverseOptions, fn VisitErrCallback) error {
+	return traverseContext(ctx, newIteratorMulti(&queue.Queue[step]{}, g, starts, opt), fn)
+}
+
+// TraverseDepthFirstContext goes through the graph in depth-first order from
+// the start nodes in turn, nodes reached from earlier starts are not visited
+// again. The traversal stops on the first error returned by fn or when
+// the context is done, and returns that error.
+func TraverseDepthFirstContext(ctx context.Context, g Graph, starts []Node, opt *TraverseOptions, fn VisitErrCallback) error {
+	// the stack pops the last start first
+	reversed := make([]Node, len(starts))
+	for i, n := range starts {
+		reversed[len(starts)-1-i] = n
+	}
+	return traverseContext(ctx, newIteratorMulti(&queue.Stack[step]{}, g, reversed, opt), fn)
+}
+
+func traverseContext(ctx context.Context, iter *iterator, fn VisitErrCallback) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		s, err := iter.next()
+		if err != nil {
+			return err
+		}
+		if s.Node == nil {
+			return nil
+		}
+		if err := fn(s.visit()); err != nil {
+			if err == ErrStop {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// BFSTree is the tree of shortest paths in hops built by a breadth-first traversal
+type BFSTree struct {
+	// Layers holds visited nodes by their distance from the root,
+	// Layers[0] is the root alone
+	Layers [][]Node
+	visits []Visit // by node ID, zero for unreached nodes
+}
+
+// BreadthFirstTree traverses the graph from the start node and returns the BFS tree
+func BreadthFirstTree(g Graph, start Node, opt *TraverseOptions) *BFSTree {
+	t := &BFSTree{visits: make([]Visit, g.MaxNodeID()+1)}
+	traverseVisit(newIteratorOpt(&queue.Queue[step]{}, g, start, opt), func(v Visit) bool {
+		if v.Depth == len(t.Layers) {
+			t.Layers = append(t.Layers, nil)
+		}
+		t.Layers[v.Depth] = append(t.Layers[v.Depth], v.Node)
+		t.visits[v.Node.ID()] = v
+		return true
+	})
+	return t
+}
+
+// Root returns the start node of the traversal, nil if the tree is empty
+func (t *BFSTree) Root() Node {
+	if len(t.Layers) == 0 {
+		return nil
+	}
+	return t.Layers[0][0]
+}
+
+// Visit returns how the node was reached, false if it was not
+func (t *BFSTree) Visit(n Node) (Visit, bool) {
+	if n.ID() >= len(t.visits) || t.visits[n.ID()].Node == nil {
+		return Visit{}, false
+	}
+	return t.visits[n.ID()], true
+}
+
+// PathTo returns edges from the root to the node along the tree,
+// nil if the node was not reached and an empty path for the root
+func (t *BFSTree) PathTo(n Node) []Edge {
+	v, ok := t.Visit(n)
+	if !ok {
+		return nil
+	}
+	path := make([]Edge, v.Depth)
+	for i := v.Depth - 1; i >= 0; i-- {
+		path[i] = v.Edge
+		v = t.visits[v.Parent.ID()]
+	}
+	return path
+}
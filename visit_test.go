@@ -0,0 +1,178 @@
+package gorka
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/iimos/gorka/gralang"
+)
+
+func TestTraverseVisit(t *testing.T) {
+	type tcase struct {
+		s     string
+		opt   *TraverseOptions
+		bfs   bool
+		visit string // label:depth:parent of visited nodes, sorted
+	}
+	cases := [...]tcase{
+		tcase{"a -> b c; b -> d; c -> d; d -> e", nil, true, "a:0: b:1:a c:1:a d:2:? e:3:d"},
+		tcase{"a -> b c; b -> d; c -> d; d -> e", &TraverseOptions{MaxDepth: 2}, true, "a:0: b:1:a c:1:a d:2:?"},
+		tcase{"a -> b c; b -> d; c -> d; d -> e", &TraverseOptions{MaxDepth: 1}, false, "a:0: b:1:a c:1:a"},
+		tcase{"a -> b -> c", &TraverseOptions{Direction: Incoming}, true, "c:0: b:1:c a:2:b"},
+		tcase{"a -> b -> c; x -> b", &TraverseOptions{Direction: Both, MaxDepth: 1}, false, "b:0: a:1:b c:1:b x:1:b"},
+	}
+
+	for i, c := range cases {
+		g := New()
+		gralang.Parse(g, c.s)
+		start, _ := g.NodeByLabel(strings.SplitN(c.visit, ":", 2)[0])
+
+		res := []string{}
+		fn := func(v Visit) bool {
+			parent := ""
+			if v.Parent != nil {
+				parent = v.Parent.Label()
+				if v.Edge == nil {
+					t.Errorf("#%d: %s has no discovering edge", i, v.Node)
+				} else if !(v.Edge.From() == v.Parent && v.Edge.Dst() == v.Node || v.Edge.From() == v.Node && v.Edge.Dst() == v.Parent) {
+					t.Errorf("#%d: wrong edge %s between %s and %s", i, v.Edge, v.Parent, v.Node)
+				}
+				if v.Node.Label() == "d" {
+					parent = "?" // reached from either b or c
+				}
+			}
+			res = append(res, v.Node.Label()+":"+string(rune('0'+v.Depth))+":"+parent)
+			return true
+		}
+		var err error
+		if c.bfs {
+			err = TraverseBreadthFirstVisit(g, start, c.opt, fn)
+		} else {
+			err = TraverseDepthFirstVisit(g, start, c.opt, fn)
+		}
+		if err != nil {
+			t.Errorf("#%d: traverse error: %s", i, err)
+		}
+
+		expected := strings.Fields(c.visit)
+		sort.Strings(res)
+		sort.Strings(expected)
+		if strings.Join(res, " ") != strings.Join(expected, " ") {
+			t.Errorf("#%d: wrong visits %v, expected %v", i, res, expected)
+		}
+	}
+}
+
+func TestBreadthFirstTree(t *testing.T) {
+	g := New()
+	gralang.Parse(g, "a -> b c; b -> d; c -> d; d -> e; x -> a")
+	a, _ := g.NodeByLabel("a")
+	e, _ := g.NodeByLabel("e")
+	x, _ := g.NodeByLabel("x")
+
+	tree := BreadthFirstTree(g, a, nil)
+	if tree.Root() != a {
+		t.Errorf("wrong root %v", tree.Root())
+	}
+	layers := []string{}
+	for _, layer := range tree.Layers {
+		labels := []string{}
+		for _, n := range layer {
+			labels = append(labels, n.Label())
+		}
+		sort.Strings(labels)
+		layers = append(layers, strings.Join(labels, ","))
+	}
+	if res := strings.Join(layers, " "); res != "a b,c d e" {
+		t.Errorf("wrong layers %s", res)
+	}
+
+	path := tree.PathTo(e)
+	if len(path) != 3 || path[0].From() != a || path[2].Dst() != e {
+		t.Errorf("wrong path to e: %v", path)
+	}
+	for i := 1; i < len(path); i++ {
+		if path[i-1].Dst() != path[i].From() {
+			t.Errorf("broken path to e: %v", path)
+		}
+	}
+	if p := tree.PathTo(a); p == nil || len(p) != 0 {
+		t.Errorf("wrong path to root: %v", p)
+	}
+	if p := tree.PathTo(x); p != nil {
+		t.Errorf("path to unreached node: %v", p)
+	}
+	if _, ok := tree.Visit(x); ok {
+		t.Errorf("unreached node is visited")
+	}
+
+	limited := BreadthFirstTree(g, a, &TraverseOptions{MaxDepth: 1})
+	if len(limited.Layers) != 2 {
+		t.Errorf("wrong layers count %d", len(limited.Layers))
+	}
+	if empty := BreadthFirstTree(g, nil, nil); empty.Root() != nil || len(empty.Layers) != 0 {
+		t.Errorf("non-empty tree without root")
+	}
+}
+
+func TestTraverseContext(t *testing.T) {
+	g := New()
+	gralang.Parse(g, "a -> b -> c -> d; x -> y -> c; z")
+	node := func(l string) Node {
+		n, _ := g.NodeByLabel(l)
+		return n
+	}
+
+	depths := []string{}
+	err := TraverseBreadthFirstContext(context.Background(), g, []Node{node("a"), node("x")}, nil, func(v Visit) error {
+		depths = append(depths, v.Node.Label()+":"+string(rune('0'+v.Depth)))
+		return nil
+	})
+	sort.Strings(depths)
+	if res := strings.Join(depths, " "); err != nil || res != "a:0 b:1 c:2 d:3 x:0 y:1" {
+		t.Errorf("wrong multi-source bfs: %s, %v", res, err)
+	}
+
+	order := []string{}
+	err = TraverseDepthFirstContext(context.Background(), g, []Node{node("x"), node("a"), node("z")}, nil, func(v Visit) error {
+		order = append(order, v.Node.Label())
+		return nil
+	})
+	if res := strings.Join(order, " "); err != nil || res != "x y c d a b z" {
+		t.Errorf("wrong multi-source dfs: %s, %v", res, err)
+	}
+
+	// callback errors are returned, ErrStop is not
+	failure := errors.New("write failed")
+	visited := 0
+	err = TraverseBreadthFirstContext(context.Background(), g, []Node{node("a")}, nil, func(v Visit) error {
+		visited++
+		if v.Node.Label() == "b" {
+			return failure
+		}
+		return nil
+	})
+	if err != failure || visited != 2 {
+		t.Errorf("wrong callback error %v after %d nodes", err, visited)
+	}
+	err = TraverseDepthFirstContext(context.Background(), g, []Node{node("a")}, nil, func(v Visit) error {
+		return ErrStop
+	})
+	if err != nil {
+		t.Errorf("ErrStop is returned: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	visited = 0
+	err = TraverseBreadthFirstContext(ctx, g, []Node{node("a")}, nil, func(v Visit) error {
+		visited++
+		cancel()
+		return nil
+	})
+	if err != context.Canceled || visited != 1 {
+		t.Errorf("wrong cancellation error %v after %d nodes", err, visited)
+	}
+}
@@ -0,0 +1,276 @@
+package walk
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/iimos/gorka/types"
+)
+
+// Options controls random walks
+type Options struct {
+	// Length of a walk in nodes including the start, default is 80.
+	// Walks reaching a node without outgoing edges end earlier.
+	Length int
+	// Walks per start node in a corpus, default is 10
+	Walks int
+	// Weighted chooses the next node with probability proportional to the edge
+	// weight, otherwise all outgoing edges are equally likely
+	Weighted bool
+	// Restart is the probability to jump back to the start on every step.
+	// With restarts walks at nodes without outgoing edges jump back too.
+	Restart float64
+	// P and Q are node2vec return and in-out parameters, 0 means 1.
+	// The edge back to the previous node is weighted by 1/P, edges to nodes
+	// adjacent to the previous node by 1 and other edges by 1/Q, so low P
+	// keeps walks local and low Q pushes them outwards.
+	P, Q float64
+	// Seed of the random generator, the same seed gives the same corpus
+	Seed int64
+	// Workers generating a corpus, default is GOMAXPROCS
+	Workers int
+}
+
+// Walker generates random walks on a graph. The graph must not be
+// modified while the walker is used.
+type Walker struct {
+	g       types.Graph
+	opt     Options
+	nodes   []types.Node // by ID
+	adj     [][]int      // destination IDs by node ID, sorted
+	weights [][]float64  // cumulative weights of adj when Weighted
+}
+
+// New prepares a walker over outgoing edges of the graph
+func New(g types.Graph, opt *Options) (*Walker, error) {
+	if g == nil {
+		return nil, errors.New("graph is empty")
+	}
+	w := &Walker{g: g}
+	if opt != nil {
+		w.opt = *opt
+	}
+	if w.opt.Length <= 0 {
+		w.opt.Length = 80
+	}
+	if w.opt.Walks <= 0 {
+		w.opt.Walks = 10
+	}
+	if w.opt.P == 0 {
+		w.opt.P = 1
+	}
+	if w.opt.Q == 0 {
+		w.opt.Q = 1
+	}
+	if w.opt.Workers <= 0 {
+		w.opt.Workers = runtime.GOMAXPROCS(0)
+	}
+	switch {
+	case w.opt.Restart < 0 || w.opt.Restart >= 1:
+		return nil, fmt.Errorf("restart probability %v is out of [0, 1)", w.opt.Restart)
+	case w.opt.P < 0 || w.opt.Q < 0:
+		return nil, errors.New("node2vec parameters must be positive")
+	}
+
+	size := g.MaxNodeID() + 1
+	w.nodes = make([]types.Node, size)
+	w.adj = make([][]int, size)
+	if w.opt.Weighted {
+		w.weights = make([][]float64, size)
+	}
+	var err error
+	g.NodeIter(func(n types.Node) bool {
+		id := n.ID()
+		w.nodes[id] = n
+		edges := make([]types.Edge, 0, g.OutDegree(n))
+		g.NodeEdgeIter(n, func(e types.Edge) bool {
+			edges = append(edges, e)
+			return true
+		})
+		sort.Slice(edges, func(i, j int) bool {
+			return edges[i].Dst().ID() < edges[j].Dst().ID()
+		})
+
+		w.adj[id] = make([]int, len(edges))
+		if w.opt.Weighted {
+			w.weights[id] = make([]float64, len(edges))
+		}
+		sum := 0.0
+		for i, e := range edges {
+			w.adj[id][i] = e.Dst().ID()
+			if w.opt.Weighted {
+				if e.Wieght() < 0 {
+					err = fmt.Errorf("edge %s has negative weight", e)
+					return false
+				}
+				sum += float64(e.Wieght())
+				w.weights[id][i] = sum
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Walk returns a random walk from the start node,
+// nil if the node is not in the graph
+func (w *Walker) Walk(start types.Node, rnd *rand.Rand) []types.Node {
+	if start == nil || start.ID() < 0 || start.ID() >= len(w.nodes) || w.nodes[start.ID()] == nil {
+		return nil
+	}
+	walk := make([]types.Node, 1, w.opt.Length)
+	walk[0] = start
+	prev, cur := noPrev, start.ID()
+	for len(walk) < w.opt.Length {
+		if w.opt.Restart > 0 && rnd.Float64() < w.opt.Restart {
+			prev, cur = noPrev, start.ID()
+			walk = append(walk, start)
+			continue
+		}
+		next, ok := w.step(prev, cur, rnd)
+		if !ok {
+			if w.opt.Restart > 0 && cur != start.ID() {
+				prev, cur = noPrev, start.ID()
+				walk = append(walk, start)
+				continue
+			}
+			break
+		}
+		prev, cur = cur, next
+		walk = append(walk, w.nodes[next])
+	}
+	return walk
+}
+
+// noPrev is the previous node of a walk at its start
+const noPrev = -1
+
+// step returns the next node ID of the walk, false at a dead end
+func (w *Walker) step(prev, cur int, rnd *rand.Rand) (int, bool) {
+	adj := w.adj[cur]
+	if len(adj) == 0 {
+		return 0, false
+	}
+	if prev == noPrev || w.opt.P == 1 && w.opt.Q == 1 {
+		if !w.opt.Weighted {
+			return adj[rnd.Intn(len(adj))], true
+		}
+		cum := w.weights[cur]
+		total := cum[len(cum)-1]
+		if total == 0 {
+			return adj[rnd.Intn(len(adj))], true
+		}
+		x := rnd.Float64() * total
+		return adj[sort.Search(len(cum)-1, func(i int) bool { return cum[i] > x })], true
+	}
+
+	// second order node2vec step
+	bias := make([]float64, len(adj))
+	total := 0.0
+	pn := w.nodes[prev]
+	for i, x := range adj {
+		b := 1.0
+		if w.opt.Weighted {
+			b = w.weights[cur][i]
+			if i > 0 {
+				b -= w.weights[cur][i-1]
+			}
+		}
+		switch {
+		case x == prev:
+			b /= w.opt.P
+		case !w.g.HasEdgeBetween(pn, w.nodes[x]):
+			b /= w.opt.Q
+		}
+		total += b
+		bias[i] = total
+	}
+	if total == 0 {
+		return adj[rnd.Intn(len(adj))], true
+	}
+	x := rnd.Float64() * total
+	return adj[sort.Search(len(bias)-1, func(i int) bool { return bias[i] > x })], true
+}
+
+// Corpus returns Walks walks from every start node, nil starts mean all
+// nodes in NodeIter order. Walks go round by round: the first walk from
+// every start, then the second one and so on. Walks are generated in
+// parallel, each one with its own generator derived from Seed, so the
+// result does not depend on the number of workers.
+func (w *Walker) Corpus(starts []types.Node) [][]types.Node {
+	if starts == nil {
+		w.g.NodeIter(func(n types.Node) bool {
+			starts = append(starts, n)
+			return true
+		})
+	}
+	walks := make([][]types.Node, len(starts)*w.opt.Walks)
+	var next int64 = -1
+	var wg sync.WaitGroup
+	for i := 0; i < w.opt.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				k := int(atomic.AddInt64(&next, 1))
+				if k >= len(walks) {
+					return
+				}
+				rnd := rand.New(rand.NewSource(walkSeed(w.opt.Seed, k)))
+				walks[k] = w.Walk(starts[k%len(starts)], rnd)
+			}
+		}()
+	}
+	wg.Wait()
+	return walks
+}
+
+// walkSeed derives the seed of the k-th walk of a corpus with splitmix64,
+// so generators of neighbouring walks and of close seeds are unrelated
+func walkSeed(seed int64, k int) int64 {
+	z := uint64(seed) + uint64(k+1)*0x9e3779b97f4a7c15
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	return int64(z ^ z>>31)
+}
+
+// Corpus generates walks from all nodes of the graph, see Walker.Corpus
+func Corpus(g types.Graph, opt *Options) ([][]types.Node, error) {
+	w, err := New(g, opt)
+	if err != nil {
+		return nil, err
+	}
+	return w.Corpus(nil), nil
+}
+
+// WriteCorpus writes walks one per line as space separated node labels,
+// unlabeled nodes are written as their IDs. This is the input format of
+// word2vec and similar tools.
+func WriteCorpus(out io.Writer, walks [][]types.Node) error {
+	b := bufio.NewWriter(out)
+	for _, walk := range walks {
+		for i, n := range walk {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			if l := n.Label(); l != "" {
+				b.WriteString(l)
+			} else {
+				b.WriteString(strconv.Itoa(n.ID()))
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return b.Flush()
+}
@@ -0,0 +1,216 @@
+package walk
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/iimos/gorka"
+	"github.com/iimos/gorka/gralang"
+	"github.com/iimos/gorka/types"
+)
+
+func parse(t *testing.T, s string) types.Graph {
+	g := gorka.New()
+	if err := gralang.Parse(g, s); err != nil {
+		t.Fatalf("parse error: %s", err)
+	}
+	return g
+}
+
+func labels(walk []types.Node) string {
+	res := make([]string, len(walk))
+	for i, n := range walk {
+		res[i] = n.Label()
+	}
+	return strings.Join(res, " ")
+}
+
+func TestWalk(t *testing.T) {
+	g := parse(t, "a -> b c; b -> c d; c -> a; d -> e")
+	w, err := New(g, &Options{Length: 20})
+	if err != nil {
+		t.Fatalf("new error: %s", err)
+	}
+	a, _ := g.NodeByLabel("a")
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		walk := w.Walk(a, rnd)
+		if walk[0] != a {
+			t.Fatalf("walk doesn't start at a: %s", labels(walk))
+		}
+		for j := 1; j < len(walk); j++ {
+			if !g.HasEdgeBetween(walk[j-1], walk[j]) {
+				t.Fatalf("no edge %s -> %s in walk %s", walk[j-1], walk[j], labels(walk))
+			}
+		}
+		if last := walk[len(walk)-1].Label(); len(walk) != 20 && last != "e" {
+			t.Fatalf("walk stopped early: %s", labels(walk))
+		}
+	}
+}
+
+func TestWalkForeignNode(t *testing.T) {
+	g := parse(t, "a -> b")
+	w, err := New(g, nil)
+	if err != nil {
+		t.Fatalf("new error: %s", err)
+	}
+	other := parse(t, "x; y; z")
+	z, _ := other.NodeByLabel("z")
+	rnd := rand.New(rand.NewSource(1))
+	if walk := w.Walk(z, rnd); len(walk) != 0 {
+		t.Errorf("walk from a node out of the graph: %s", labels(walk))
+	}
+	if walk := w.Walk(nil, rnd); len(walk) != 0 {
+		t.Errorf("walk from nil node: %s", labels(walk))
+	}
+}
+
+func TestCorpusDeterministic(t *testing.T) {
+	g, _ := gorka.NewRandom(50, 0.1)
+	c1, err := Corpus(g, &Options{Seed: 5, Workers: 1, Length: 10, Walks: 3})
+	if err != nil {
+		t.Fatalf("corpus error: %s", err)
+	}
+	c2, _ := Corpus(g, &Options{Seed: 5, Workers: 4, Length: 10, Walks: 3})
+	if len(c1) != 150 || !reflect.DeepEqual(c1, c2) {
+		t.Errorf("corpus depends on workers")
+	}
+	c3, _ := Corpus(g, &Options{Seed: 6, Workers: 4, Length: 10, Walks: 3})
+	if reflect.DeepEqual(c1, c3) {
+		t.Errorf("corpus doesn't depend on seed")
+	}
+	for i, walk := range c1 {
+		if len(walk) > 0 && walk[0].ID() != i%50+1 {
+			t.Errorf("walk %d starts at %s", i, walk[0])
+		}
+	}
+}
+
+// frequency returns how often the second node of walks from start is next
+func frequency(t *testing.T, g types.Graph, opt *Options, start, at string, pos int) float64 {
+	w, err := New(g, opt)
+	if err != nil {
+		t.Fatalf("new error: %s", err)
+	}
+	s, _ := g.NodeByLabel(start)
+	rnd := rand.New(rand.NewSource(1))
+	hits := 0
+	const count = 2000
+	for i := 0; i < count; i++ {
+		walk := w.Walk(s, rnd)
+		if len(walk) > pos && walk[pos].Label() == at {
+			hits++
+		}
+	}
+	return float64(hits) / count
+}
+
+func TestWalkSeed(t *testing.T) {
+	seen := map[int64]bool{}
+	for _, seed := range []int64{0, 1, 1000003, -1} {
+		for k := 0; k < 1000; k++ {
+			s := walkSeed(seed, k)
+			if seen[s] {
+				t.Fatalf("seed %d, walk %d: repeated seed %d", seed, k, s)
+			}
+			seen[s] = true
+		}
+	}
+}
+
+func TestWeighted(t *testing.T) {
+	g := gorka.New()
+	a, _ := g.NewNode("a")
+	b, _ := g.NewNode("b")
+	c, _ := g.NewNode("c")
+	g.AddEdge(a, b, 9)
+	g.AddEdge(a, c, 1)
+
+	if f := frequency(t, g, &Options{Length: 2, Weighted: true}, "a", "b", 1); f < 0.85 || f > 0.95 {
+		t.Errorf("weighted: b is chosen with frequency %f", f)
+	}
+	if f := frequency(t, g, &Options{Length: 2}, "a", "b", 1); f < 0.45 || f > 0.55 {
+		t.Errorf("uniform: b is chosen with frequency %f", f)
+	}
+
+	g.AddEdge(a, c, -1)
+	if _, err := New(g, &Options{Weighted: true}); err == nil {
+		t.Errorf("no error for negative weight")
+	}
+}
+
+func TestNode2Vec(t *testing.T) {
+	g := parse(t, "a -- b -- c")
+	if f := frequency(t, g, &Options{Length: 3, P: 0.01}, "a", "a", 2); f < 0.95 {
+		t.Errorf("low p: walk returns with frequency %f", f)
+	}
+	if f := frequency(t, g, &Options{Length: 3, Q: 0.01}, "a", "c", 2); f < 0.95 {
+		t.Errorf("low q: walk goes out with frequency %f", f)
+	}
+	if f := frequency(t, g, &Options{Length: 3}, "a", "c", 2); f < 0.4 || f > 0.6 {
+		t.Errorf("p = q = 1: walk goes out with frequency %f", f)
+	}
+
+	// triangle: neighbours of the previous node are weighted by 1
+	g = parse(t, "a -- b -- c -- a; b -- d")
+	w, _ := New(g, &Options{P: 1000, Q: 1000})
+	a, _ := g.NodeByLabel("a")
+	b, _ := g.NodeByLabel("b")
+	rnd := rand.New(rand.NewSource(1))
+	hits := 0
+	for i := 0; i < 100; i++ {
+		if next, ok := w.step(a.ID(), b.ID(), rnd); ok && w.nodes[next].Label() == "c" {
+			hits++
+		}
+	}
+	if hits < 95 {
+		t.Errorf("triangle: c is chosen %d times of 100", hits)
+	}
+}
+
+func TestRestart(t *testing.T) {
+	g := parse(t, "a -> b -> c -> d -> e -> f -> g -> h")
+	w, err := New(g, &Options{Length: 1000, Restart: 0.5})
+	if err != nil {
+		t.Fatalf("new error: %s", err)
+	}
+	a, _ := g.NodeByLabel("a")
+	walk := w.Walk(a, rand.New(rand.NewSource(1)))
+	if len(walk) != 1000 {
+		// dead end at h restarts too
+		t.Fatalf("walk with restarts ended at %d", len(walk))
+	}
+	starts := 0
+	for i, n := range walk {
+		if n == a {
+			starts++
+		} else if !g.HasEdgeBetween(walk[i-1], n) {
+			t.Fatalf("no edge %s -> %s", walk[i-1], n)
+		}
+	}
+	if starts < 400 || starts > 600 {
+		t.Errorf("wrong restarts count %d", starts)
+	}
+
+	for _, r := range []float64{-0.1, 1} {
+		if _, err := New(g, &Options{Restart: r}); err == nil {
+			t.Errorf("no error for restart %v", r)
+		}
+	}
+}
+
+func TestWriteCorpus(t *testing.T) {
+	g := gorka.New()
+	a, _ := g.NewNode("a")
+	b, _ := g.NewNode("")
+	var s strings.Builder
+	if err := WriteCorpus(&s, [][]types.Node{{a, b, a}, {b}}); err != nil {
+		t.Fatalf("write error: %s", err)
+	}
+	if s.String() != "a 2 a\n2\n" {
+		t.Errorf("wrong corpus %q", s.String())
+	}
+}